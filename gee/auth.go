@@ -0,0 +1,95 @@
+package gee
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// AuthUserKey is the context key holding the authenticated user
+const AuthUserKey = "user"
+
+// Accounts maps user names to passwords for BasicAuth
+type Accounts map[string]string
+
+type authPair struct {
+	value string
+	user  string
+}
+
+// BasicAuth returns a Basic HTTP Authorization middleware for the "Authorization Required" realm
+func BasicAuth(accounts Accounts) HandlerFunc {
+	return BasicAuthForRealm(accounts, "")
+}
+
+// BasicAuthForRealm stores the matched user name under AuthUserKey
+func BasicAuthForRealm(accounts Accounts, realm string) HandlerFunc {
+	if realm == "" {
+		realm = "Authorization Required"
+	}
+	realm = "Basic realm=" + strconv.Quote(realm)
+	pairs := make([]authPair, 0, len(accounts))
+	for user, password := range accounts {
+		if user == "" {
+			panic("gee: BasicAuth user can not be empty")
+		}
+		pairs = append(pairs, authPair{
+			value: "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password)),
+			user:  user,
+		})
+	}
+
+	return func(c *Context) {
+		header := c.Req.Header.Get("Authorization")
+		// compare against every pair so the time spent does not depend on which user matched
+		user, found := "", false
+		for _, pair := range pairs {
+			if subtle.ConstantTimeCompare([]byte(pair.value), []byte(header)) == 1 {
+				user, found = pair.user, true
+			}
+		}
+		if !found {
+			c.SetHeader("WWW-Authenticate", realm)
+			c.Fail(http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		c.Set(AuthUserKey, user)
+		c.Next()
+	}
+}
+
+// TokenValidator checks a bearer token and returns the identity it belongs to
+type TokenValidator func(c *Context, token string) (user interface{}, err error)
+
+// BearerAuth extracts the token of an "Authorization: Bearer <token>" header,
+// validates it and stores the returned identity under AuthUserKey. The
+// error of the validator is not sent to the client.
+func BearerAuth(validate TokenValidator) HandlerFunc {
+	return func(c *Context) {
+		token, ok := bearerToken(c.Req)
+		if !ok {
+			c.SetHeader("WWW-Authenticate", "Bearer")
+			c.Fail(http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		user, err := validate(c, token)
+		if err != nil {
+			c.SetHeader("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.Fail(http.StatusUnauthorized, "Unauthorized")
+			return
+		}
+		c.Set(AuthUserKey, user)
+		c.Next()
+	}
+}
+
+func bearerToken(req *http.Request) (string, bool) {
+	header := req.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(header[len(prefix):]), true
+}
@@ -0,0 +1,150 @@
+package gee
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func serveAuth(r *Engine, header string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/admin/me", nil)
+	if header != "" {
+		req.Header.Set("Authorization", header)
+	}
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func newAuthEngine(auth HandlerFunc) *Engine {
+	r := New()
+	admin := r.Group("/admin")
+	admin.Use(auth)
+	admin.GET("/me", func(c *Context) {
+		user, _ := c.Get(AuthUserKey)
+		c.String(http.StatusOK, "%v", user)
+	})
+	return r
+}
+
+func TestBasicAuth(t *testing.T) {
+	r := newAuthEngine(BasicAuthForRealm(Accounts{"foo": "bar", "admin": "secret"}, "gee"))
+
+	w := serveAuth(r, "Basic "+base64.StdEncoding.EncodeToString([]byte("admin:secret")))
+	if w.Code != http.StatusOK || w.Body.String() != "admin" {
+		t.Fatalf("expected admin to be authorized, got %d %q", w.Code, w.Body.String())
+	}
+
+	w = serveAuth(r, "Basic "+base64.StdEncoding.EncodeToString([]byte("admin:wrong")))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong password should be rejected, got %d", w.Code)
+	}
+	if got := w.Header().Get("WWW-Authenticate"); got != `Basic realm="gee"` {
+		t.Fatalf("WWW-Authenticate = %q", got)
+	}
+}
+
+func TestBearerAuth(t *testing.T) {
+	r := newAuthEngine(BearerAuth(func(c *Context, token string) (interface{}, error) {
+		if token != "t0ken" {
+			return nil, errors.New("unknown token")
+		}
+		return "svc", nil
+	}))
+
+	if w := serveAuth(r, "Bearer t0ken"); w.Code != http.StatusOK || w.Body.String() != "svc" {
+		t.Fatalf("expected svc to be authorized, got %d %q", w.Code, w.Body.String())
+	}
+	if w := serveAuth(r, "Bearer nope"); w.Code != http.StatusUnauthorized || strings.Contains(w.Body.String(), "unknown token") {
+		t.Fatalf("invalid token should be rejected without the validator error, got %d %q", w.Code, w.Body.String())
+	}
+	if w := serveAuth(r, ""); w.Code != http.StatusUnauthorized {
+		t.Fatalf("missing token should be rejected, got %d", w.Code)
+	}
+}
+
+func signTestJWT(t *testing.T, alg string, claims Claims, sign func([]byte) []byte) string {
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sign([]byte(unsigned)))
+}
+
+func TestParseJWT(t *testing.T) {
+	secret := []byte("s3cret")
+	hs256 := func(b []byte) []byte {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(b)
+		return mac.Sum(nil)
+	}
+	now := time.Unix(1700000000, 0)
+	config := JWTConfig{Secret: secret, Now: func() time.Time { return now }}
+
+	token := signTestJWT(t, "HS256", Claims{"sub": "tom", "exp": now.Add(time.Minute).Unix()}, hs256)
+	claims, err := ParseJWT(token, config)
+	if err != nil || claims.Subject() != "tom" {
+		t.Fatalf("ParseJWT = %v, %v", claims, err)
+	}
+
+	expired := signTestJWT(t, "HS256", Claims{"exp": now.Add(-time.Minute).Unix()}, hs256)
+	if _, err := ParseJWT(expired, config); err != ErrTokenExpired {
+		t.Fatalf("expected ErrTokenExpired, got %v", err)
+	}
+	forged := signTestJWT(t, "HS256", Claims{"sub": "admin"}, func(b []byte) []byte {
+		mac := hmac.New(sha256.New, []byte("guess"))
+		mac.Write(b)
+		return mac.Sum(nil)
+	})
+	if _, err := ParseJWT(forged, config); err != ErrTokenSignature {
+		t.Fatalf("expected ErrTokenSignature, got %v", err)
+	}
+	none := signTestJWT(t, "none", Claims{"sub": "tom"}, func([]byte) []byte { return nil })
+	if _, err := ParseJWT(none, config); err != ErrTokenAlgorithm {
+		t.Fatalf("expected ErrTokenAlgorithm, got %v", err)
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs256 := signTestJWT(t, "RS256", Claims{"sub": "jack"}, func(b []byte) []byte {
+		sum := sha256.Sum256(b)
+		sig, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+		return sig
+	})
+	if _, err := ParseJWT(rs256, config); err != ErrTokenAlgorithm {
+		t.Fatalf("RS256 must be rejected without a public key, got %v", err)
+	}
+	if _, err := ParseJWT(signTestJWT(t, "HS256", Claims{"sub": "tom"}, func(b []byte) []byte {
+		mac := hmac.New(sha256.New, nil)
+		mac.Write(b)
+		return mac.Sum(nil)
+	}), JWTConfig{Secret: []byte{}}); err != ErrTokenAlgorithm {
+		t.Fatalf("HS256 must be rejected with an empty secret, got %v", err)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("JWT should panic on an empty secret")
+			}
+		}()
+		JWT(JWTConfig{Secret: []byte{}})
+	}()
+	r := newAuthEngine(JWT(JWTConfig{PublicKey: &key.PublicKey}))
+	if w := serveAuth(r, "Bearer "+rs256); w.Code != http.StatusOK || w.Body.String() != "jack" {
+		t.Fatalf("expected jack to be authorized, got %d %q", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,304 @@
+package gee
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"geecache"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheConfig configures the response cache
+type CacheConfig struct {
+	// Group stores the responses. Created with ResponseGetter as getter,
+	// the peers of the group share them.
+	Group *geecache.Group
+	// TTL is how long responses are kept, 1 minute by default. A shorter
+	// max-age or s-maxage of the response wins.
+	TTL time.Duration
+	// Routes overrides TTL per route, keyed by "GET pattern", e.g.
+	// "GET /users/:id". A negative TTL disables caching of the route.
+	Routes map[string]time.Duration
+	// Vary lists the request headers part of the key, Accept and
+	// Accept-Encoding by default. Responses varying on other headers are
+	// not cached.
+	Vary []string
+	// CacheCookies also caches requests carrying cookies. Their responses
+	// are shared by every client, so only set it when handlers do not
+	// personalize pages from cookies.
+	CacheCookies bool
+}
+
+// Cache caches GET responses in group for ttl
+func Cache(group *geecache.Group, ttl time.Duration) HandlerFunc {
+	return CacheWithConfig(CacheConfig{Group: group, TTL: ttl})
+}
+
+type cacheBypassKey struct{}
+
+// cacheEntry is a stored response, or a marker remembering a response
+// could not be cached
+type cacheEntry struct {
+	Uncacheable bool        `json:"u,omitempty"`
+	Status      int         `json:"s,omitempty"`
+	Header      http.Header `json:"h,omitempty"`
+	Body        []byte      `json:"b,omitempty"`
+	Stored      time.Time   `json:"t"`
+	Expires     time.Time   `json:"e"`
+}
+
+// cacheKey identifies a response. Keys embed the TTL window they belong
+// to, since geecache values never expire, and everything ResponseGetter
+// needs to replay the request.
+type cacheKey struct {
+	ttl    time.Duration
+	window int64
+	host   string
+	names  []string   // the Vary headers of the config
+	vary   url.Values // their values
+	uri    string
+}
+
+func (k cacheKey) String() string {
+	return strings.Join([]string{"gee", strconv.FormatInt(int64(k.ttl), 10), strconv.FormatInt(k.window, 10), k.host,
+		strings.Join(k.names, ","), k.vary.Encode(), k.uri}, "|")
+}
+
+func parseCacheKey(s string) (k cacheKey, err error) {
+	parts := strings.SplitN(s, "|", 7)
+	if len(parts) != 7 || parts[0] != "gee" {
+		return k, errors.New("gee: not a response cache key")
+	}
+	ttl, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return k, err
+	}
+	if k.window, err = strconv.ParseInt(parts[2], 10, 64); err != nil {
+		return k, err
+	}
+	if parts[4] != "" {
+		k.names = strings.Split(parts[4], ",")
+	}
+	if k.vary, err = url.ParseQuery(parts[5]); err != nil {
+		return k, err
+	}
+	k.ttl, k.host, k.uri = time.Duration(ttl), parts[3], parts[6]
+	return k, nil
+}
+
+// windowEnd is when the responses stored under the key expire at the latest
+func (k cacheKey) windowEnd() time.Time {
+	return time.Unix(0, (k.window+1)*int64(k.ttl))
+}
+
+// ResponseGetter returns a geecache Getter producing the responses of
+// handler, usually the Engine using the cache, so a node missing a
+// response asks the peer owning its key, which replays the request. The
+// replayed request carries the host, URI and Vary headers only. A response
+// found uncacheable this way is produced twice the first time, by the
+// replay and by the request itself.
+func ResponseGetter(handler http.Handler) geecache.Getter {
+	return geecache.GetterFunc(func(key string) ([]byte, error) {
+		k, err := parseCacheKey(key)
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequest(http.MethodGet, "http://"+k.host+k.uri, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Host, req.RequestURI = k.host, k.uri
+		for name, values := range k.vary {
+			req.Header[name] = values
+		}
+		req = req.WithContext(context.WithValue(req.Context(), cacheBypassKey{}, true))
+		rec := newResponseRecorder()
+		handler.ServeHTTP(rec, req)
+		return json.Marshal(newCacheEntry(rec, k, time.Now()))
+	})
+}
+
+// CacheWithConfig returns a middleware serving GET responses from a
+// geecache Group. Requests with Authorization, cookies (unless
+// CacheCookies) or Cache-Control no-store skip the cache and no-cache
+// refreshes it. Responses are stored unless they are errors other than 404,
+// set cookies, or say no-store, private or no-cache. Hits answer
+// If-None-Match with 304 and carry Age and X-Cache.
+func CacheWithConfig(config CacheConfig) HandlerFunc {
+	if config.Group == nil {
+		panic("gee: cache needs a geecache group")
+	}
+	if config.TTL <= 0 {
+		config.TTL = time.Minute
+	}
+	if config.Vary == nil {
+		config.Vary = []string{"Accept", "Accept-Encoding"}
+	}
+	names := make([]string, len(config.Vary))
+	for i, name := range config.Vary {
+		names[i] = http.CanonicalHeaderKey(name)
+	}
+
+	return func(c *Context) {
+		if c.Method != http.MethodGet || c.FullPath() == "" || c.Req.Context().Value(cacheBypassKey{}) != nil ||
+			c.Req.Header.Get("Authorization") != "" || (!config.CacheCookies && c.Req.Header.Get("Cookie") != "") {
+			c.Next()
+			return
+		}
+		ttl := config.TTL
+		if routeTTL, ok := config.Routes[c.Method+" "+c.FullPath()]; ok {
+			ttl = routeTTL
+		}
+		directives := parseCacheControl(c.Req.Header.Get("Cache-Control"))
+		if _, ok := directives["no-store"]; ok || ttl < 0 {
+			c.Next()
+			return
+		}
+
+		now := time.Now()
+		k := cacheKey{ttl: ttl, window: now.UnixNano() / int64(ttl), host: c.Req.Host, names: names, vary: url.Values{}, uri: c.Req.URL.RequestURI()}
+		for _, name := range names {
+			if values := c.Req.Header.Values(name); len(values) > 0 {
+				k.vary[name] = values
+			}
+		}
+		key := k.String()
+
+		if _, refresh := directives["no-cache"]; !refresh {
+			if view, err := config.Group.Get(key); err == nil {
+				var entry cacheEntry
+				if json.Unmarshal(view.ByteSlice(), &entry) == nil {
+					if entry.Uncacheable {
+						c.Next()
+						return
+					}
+					if now.Before(entry.Expires) {
+						serveCacheEntry(c, &entry, now)
+						return
+					}
+				}
+			}
+		}
+
+		// miss: run the chain into a buffer and store what it produced
+		rec := nextRecorded(c)
+		entry := newCacheEntry(rec, k, now)
+		if b, err := json.Marshal(entry); err == nil {
+			config.Group.Set(key, b)
+		}
+		c.SetHeader("X-Cache", "MISS")
+		rec.writeTo(c.Writer)
+	}
+}
+
+// newCacheEntry stores rec, or marks it uncacheable
+func newCacheEntry(rec *responseRecorder, k cacheKey, now time.Time) *cacheEntry {
+	entry := &cacheEntry{Stored: now, Expires: k.windowEnd()}
+	vary := make(map[string]bool, len(k.names))
+	for _, name := range k.names {
+		vary[name] = true
+	}
+	switch rec.statusCode() {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent, http.StatusMovedPermanently,
+		http.StatusNotFound, http.StatusGone, http.StatusPermanentRedirect:
+	default:
+		entry.Uncacheable = true
+	}
+	if rec.header.Get("Set-Cookie") != "" {
+		entry.Uncacheable = true
+	}
+	directives := parseCacheControl(rec.header.Get("Cache-Control"))
+	for _, d := range []string{"no-store", "private", "no-cache"} {
+		if _, ok := directives[d]; ok {
+			entry.Uncacheable = true
+		}
+	}
+	for _, value := range rec.header.Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "*" || (name != "" && !vary[name]) {
+				entry.Uncacheable = true
+			}
+		}
+	}
+	maxAge, ok := directives["s-maxage"]
+	if !ok {
+		maxAge, ok = directives["max-age"]
+	}
+	if ok {
+		if seconds, err := strconv.Atoi(maxAge); err == nil {
+			if expires := now.Add(time.Duration(seconds) * time.Second); expires.Before(entry.Expires) {
+				entry.Expires = expires
+			}
+		}
+	}
+	if !now.Before(entry.Expires) {
+		entry.Uncacheable = true
+	}
+	if !entry.Uncacheable {
+		entry.Status, entry.Header, entry.Body = rec.statusCode(), rec.header, rec.body.Bytes()
+	}
+	return entry
+}
+
+func serveCacheEntry(c *Context, entry *cacheEntry, now time.Time) {
+	header := c.Writer.Header()
+	for name, values := range entry.Header {
+		header[name] = values
+	}
+	header.Set("Age", strconv.FormatInt(int64(now.Sub(entry.Stored)/time.Second), 10))
+	header.Set("X-Cache", "HIT")
+	c.Abort()
+	if etag := entry.Header.Get("ETag"); etag != "" && etagMatch(c.Req.Header.Get("If-None-Match"), etag, true) {
+		c.notModified()
+		return
+	}
+	c.Status(entry.Status)
+	c.Writer.Write(entry.Body)
+}
+
+// parseCacheControl returns the lower-cased directives of a Cache-Control
+// header with their values
+func parseCacheControl(header string) map[string]string {
+	directives := make(map[string]string)
+	for _, part := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name != "" {
+			directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+		}
+	}
+	return directives
+}
+
+// etagMatch reports whether an If-Match or If-None-Match header lists
+// etag, comparing weakly (ignoring W/) when weak is set
+func etagMatch(header, etag string, weak bool) bool {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+	if weak {
+		etag = strings.TrimPrefix(etag, "W/")
+	} else if strings.HasPrefix(etag, "W/") {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		} else if strings.HasPrefix(candidate, "W/") {
+			continue
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
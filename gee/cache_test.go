@@ -0,0 +1,114 @@
+package gee
+
+import (
+	"errors"
+	"geecache"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	group := geecache.NewGroup("gee-cache-test", 1<<20, geecache.GetterFunc(func(key string) ([]byte, error) {
+		return nil, errors.New("not found")
+	}))
+	calls := 0
+	r := New()
+	r.Use(CacheWithConfig(CacheConfig{Group: group, Routes: map[string]time.Duration{"GET /live": -1}}))
+	r.GET("/users/:id", func(c *Context) {
+		calls++
+		c.SetHeader("ETag", `"v1"`)
+		c.String(http.StatusOK, "%s %s", c.Param("id"), c.Req.Header.Get("Accept"))
+	})
+	r.GET("/private", func(c *Context) {
+		calls++
+		c.SetHeader("Cache-Control", "private")
+		c.String(http.StatusOK, "secret")
+	})
+	r.GET("/live", func(c *Context) {
+		calls++
+		c.String(http.StatusOK, "live")
+	})
+
+	get := func(path, accept, inm string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		if inm != "" {
+			req.Header.Set("If-None-Match", inm)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get("/users/1", "", ""); w.Header().Get("X-Cache") != "MISS" || w.Body.String() != "1 " {
+		t.Fatalf("first request should miss, got %q %q", w.Header().Get("X-Cache"), w.Body.String())
+	}
+	if w := get("/users/1", "", ""); w.Header().Get("X-Cache") != "HIT" || w.Body.String() != "1 " || w.Header().Get("Age") == "" || calls != 1 {
+		t.Fatalf("second request should hit, got %q %q after %d calls", w.Header().Get("X-Cache"), w.Body.String(), calls)
+	}
+	if w := get("/users/1", "text/plain", ""); w.Body.String() != "1 text/plain" || calls != 2 {
+		t.Fatalf("Vary headers should be part of the key, got %q", w.Body.String())
+	}
+	if w := get("/users/1", "", `W/"v1"`); w.Code != http.StatusNotModified || w.Body.Len() != 0 || calls != 2 {
+		t.Fatalf("matching If-None-Match should get 304, got %d", w.Code)
+	}
+	for _, path := range []string{"/private", "/private", "/live", "/live"} {
+		get(path, "", "")
+	}
+	if calls != 6 {
+		t.Fatalf("private and disabled routes should not be cached, %d calls", calls)
+	}
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("GET", "/users/2", nil)
+		req.Header.Set("Cookie", "session=tom")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Header().Get("X-Cache") != "" {
+			t.Fatal("requests with cookies should skip the cache")
+		}
+	}
+	if calls != 8 {
+		t.Fatalf("requests with cookies should reach the handler, %d calls", calls)
+	}
+}
+
+func TestResponseGetter(t *testing.T) {
+	calls := 0
+	r := New()
+	group := geecache.NewGroup("gee-cache-getter-test", 1<<20, ResponseGetter(r))
+	r.Use(Cache(group, time.Hour))
+	r.GET("/hello", func(c *Context) {
+		calls++
+		c.String(http.StatusOK, "hello %s", c.Req.Host)
+	})
+
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/hello", nil))
+		if w.Body.String() != "hello example.com" || w.Header().Get("X-Cache") != "HIT" {
+			t.Fatalf("got %q %q", w.Body.String(), w.Header().Get("X-Cache"))
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("the response should be produced once, got %d calls", calls)
+	}
+}
+
+func TestCachePanic(t *testing.T) {
+	group := geecache.NewGroup("gee-cache-panic-test", 1<<20, geecache.GetterFunc(func(key string) ([]byte, error) {
+		return nil, errors.New("not found")
+	}))
+	r := New()
+	r.Use(Recovery(), Cache(group, time.Minute))
+	r.GET("/p", func(c *Context) { panic("boom") })
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/p", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("panics should reach Recovery, got %d", w.Code)
+	}
+}
@@ -0,0 +1,197 @@
+package gee
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// SetTrustedProxies sets the IPs or CIDRs of the load balancers in front
+// of the engine. Forwarded, X-Forwarded-For, X-Real-IP, X-Forwarded-Proto
+// and X-Forwarded-Host are only honored when the peer is one of them.
+func (engine *Engine) SetTrustedProxies(proxies []string) error {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return fmt.Errorf("gee: invalid trusted proxy %q", proxy)
+			}
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			proxy = fmt.Sprintf("%s/%d", proxy, bits)
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return fmt.Errorf("gee: invalid trusted proxy %q: %v", proxy, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	engine.trustedProxies = nets
+	return nil
+}
+
+func (engine *Engine) isTrustedProxy(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, n := range engine.trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoteIP returns the IP address of the immediate peer
+func (c *Context) RemoteIP() string {
+	ip, _, err := net.SplitHostPort(strings.TrimSpace(c.Req.RemoteAddr))
+	if err != nil {
+		return strings.TrimSpace(c.Req.RemoteAddr)
+	}
+	return ip
+}
+
+// fromTrustedProxy reports whether forwarded headers can be believed
+func (c *Context) fromTrustedProxy() bool {
+	return c.engine != nil && c.engine.isTrustedProxy(net.ParseIP(c.RemoteIP()))
+}
+
+// ClientIP returns the address of the client. Behind trusted proxies the
+// forwarding chain is walked from the nearest hop and the first address
+// that is not a trusted proxy is returned.
+func (c *Context) ClientIP() string {
+	remote := c.RemoteIP()
+	if !c.fromTrustedProxy() {
+		return remote
+	}
+
+	var chain []string
+	if elements := c.forwarded(); len(elements) > 0 {
+		for _, element := range elements {
+			chain = append(chain, forwardedNode(element["for"]))
+		}
+	} else if header := c.Req.Header.Values("X-Forwarded-For"); len(header) > 0 {
+		for _, ip := range strings.Split(strings.Join(header, ","), ",") {
+			chain = append(chain, strings.TrimSpace(ip))
+		}
+	} else if ip := strings.TrimSpace(c.Req.Header.Get("X-Real-IP")); net.ParseIP(ip) != nil {
+		return ip
+	}
+
+	if i := c.clientHop(chain); i >= 0 && net.ParseIP(chain[i]) != nil {
+		return chain[i]
+	}
+	return remote
+}
+
+// forwarded parses every Forwarded header line, proxies append their
+// element to the last line or add a new one
+func (c *Context) forwarded() []map[string]string {
+	return parseForwarded(strings.Join(c.Req.Header.Values("Forwarded"), ","))
+}
+
+// clientHop walks a forwarding chain from the nearest hop past the
+// trusted proxies and returns the index it stops at: the client, or an
+// unknown or obfuscated hop that can not be walked past. The element at
+// that index was written by a trusted proxy.
+func (c *Context) clientHop(chain []string) int {
+	for i := len(chain) - 1; i >= 0; i-- {
+		ip := net.ParseIP(chain[i])
+		if ip == nil || i == 0 || !c.engine.isTrustedProxy(ip) {
+			return i
+		}
+	}
+	return -1
+}
+
+// forwardedParam returns a parameter of the Forwarded element written by
+// the proxy the client connected to
+func (c *Context) forwardedParam(name string) string {
+	elements := c.forwarded()
+	chain := make([]string, len(elements))
+	for i, element := range elements {
+		chain[i] = forwardedNode(element["for"])
+	}
+	if i := c.clientHop(chain); i >= 0 {
+		return elements[i][name]
+	}
+	return ""
+}
+
+// lastValue returns the value of a comma-separated header added by the
+// nearest hop
+func lastValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	list := strings.Split(values[len(values)-1], ",")
+	return strings.TrimSpace(list[len(list)-1])
+}
+
+// Scheme returns "https" or "http" as requested by the client. Forwarded
+// values come from the proxy the client connected to, X-Forwarded-Proto
+// and -Host from the nearest hop.
+func (c *Context) Scheme() string {
+	if c.fromTrustedProxy() {
+		if proto := strings.ToLower(c.forwardedParam("proto")); proto == "http" || proto == "https" {
+			return proto
+		}
+		if proto := strings.ToLower(lastValue(c.Req.Header.Values("X-Forwarded-Proto"))); proto == "http" || proto == "https" {
+			return proto
+		}
+	}
+	if c.Req.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// Host returns the host, with port if any, requested by the client
+func (c *Context) Host() string {
+	if c.fromTrustedProxy() {
+		if host := c.forwardedParam("host"); host != "" {
+			return host
+		}
+		if host := lastValue(c.Req.Header.Values("X-Forwarded-Host")); host != "" {
+			return host
+		}
+	}
+	return c.Req.Host
+}
+
+// parseForwarded parses an RFC 7239 Forwarded header into one map of
+// lower-cased parameters per element, ordered from the client to the nearest proxy
+func parseForwarded(header string) []map[string]string {
+	if header == "" {
+		return nil
+	}
+	var elements []map[string]string
+	for _, element := range strings.Split(header, ",") {
+		params := make(map[string]string)
+		for _, pair := range strings.Split(element, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok {
+				continue
+			}
+			params[strings.ToLower(key)] = strings.Trim(value, `"`)
+		}
+		elements = append(elements, params)
+	}
+	return elements
+}
+
+// forwardedNode strips the port and IPv6 brackets of a Forwarded "for" value
+func forwardedNode(node string) string {
+	if strings.HasPrefix(node, "[") {
+		if end := strings.IndexByte(node, ']'); end > 0 {
+			return node[1:end]
+		}
+	}
+	if host, _, err := net.SplitHostPort(node); err == nil {
+		return host
+	}
+	return node
+}
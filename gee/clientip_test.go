@@ -0,0 +1,87 @@
+package gee
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	engine := New()
+	if err := engine.SetTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.SetTrustedProxies([]string{"not-an-ip"}); err == nil {
+		t.Fatal("invalid proxies should be rejected")
+	}
+	engine.SetTrustedProxies([]string{"10.0.0.0/8", "192.168.1.1", "2001:db8::/32"})
+
+	for _, tc := range []struct {
+		remote  string
+		headers map[string]string
+		want    string
+	}{
+		{"1.2.3.4:80", map[string]string{"X-Forwarded-For": "5.6.7.8"}, "1.2.3.4"},
+		{"10.0.0.1:80", map[string]string{"X-Forwarded-For": "5.6.7.8"}, "5.6.7.8"},
+		{"10.0.0.1:80", map[string]string{"X-Forwarded-For": "9.9.9.9, 5.6.7.8, 10.0.0.2"}, "5.6.7.8"},
+		{"10.0.0.1:80", map[string]string{"X-Forwarded-For": "10.0.0.3, 10.0.0.2"}, "10.0.0.3"},
+		{"192.168.1.1:80", map[string]string{"X-Real-IP": "5.6.7.8"}, "5.6.7.8"},
+		{"192.168.1.2:80", map[string]string{"X-Real-IP": "5.6.7.8"}, "192.168.1.2"},
+		{"10.0.0.1:80", map[string]string{"Forwarded": `for=5.6.7.8;proto=https, for="[2001:db8::1]:4711"`}, "5.6.7.8"},
+		{"10.0.0.1:80", map[string]string{"Forwarded": `for=unknown`}, "10.0.0.1"},
+	} {
+		c := newContext(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		c.engine = engine
+		c.Req.RemoteAddr = tc.remote
+		for k, v := range tc.headers {
+			c.Req.Header.Set(k, v)
+		}
+		if got := c.ClientIP(); got != tc.want {
+			t.Fatalf("ClientIP() with %s %v = %s, want %s", tc.remote, tc.headers, got, tc.want)
+		}
+	}
+}
+
+func TestSchemeAndHost(t *testing.T) {
+	engine := New()
+	engine.SetTrustedProxies([]string{"10.0.0.1"})
+
+	c := newContext(httptest.NewRecorder(), httptest.NewRequest("GET", "http://internal/", nil))
+	c.engine = engine
+	c.Req.Header.Set("X-Forwarded-Proto", "https")
+	c.Req.Header.Set("X-Forwarded-Host", "example.com")
+
+	c.Req.RemoteAddr = "1.2.3.4:80"
+	if c.Scheme() != "http" || c.Host() != "internal" {
+		t.Fatal("untrusted peers must not override scheme and host")
+	}
+	c.Req.RemoteAddr = "10.0.0.1:80"
+	if c.Scheme() != "https" || c.Host() != "example.com" {
+		t.Fatalf("got %s://%s", c.Scheme(), c.Host())
+	}
+	c.Req.Header.Set("Forwarded", `proto=http;host="api.example.com"`)
+	if c.Scheme() != "http" || c.Host() != "api.example.com" {
+		t.Fatalf("Forwarded should take precedence, got %s://%s", c.Scheme(), c.Host())
+	}
+}
+
+func TestForwardedSpoofing(t *testing.T) {
+	engine := New()
+	engine.SetTrustedProxies([]string{"10.0.0.0/8"})
+	c := newContext(httptest.NewRecorder(), httptest.NewRequest("GET", "http://internal/", nil))
+	c.engine = engine
+	c.Req.RemoteAddr = "10.0.0.1:80"
+
+	// the client sends the first line, the proxy appends the second
+	c.Req.Header.Add("Forwarded", `for=6.6.6.6;host=evil.com;proto=http`)
+	c.Req.Header.Add("Forwarded", `for=1.2.3.4;host=real.com;proto=https`)
+	if c.ClientIP() != "1.2.3.4" || c.Host() != "real.com" || c.Scheme() != "https" {
+		t.Fatalf("got %s %s://%s", c.ClientIP(), c.Scheme(), c.Host())
+	}
+
+	c.Req.Header.Del("Forwarded")
+	c.Req.Header.Set("X-Forwarded-Host", "evil.com, real.com")
+	c.Req.Header.Set("X-Forwarded-Proto", "http, https")
+	if c.Host() != "real.com" || c.Scheme() != "https" {
+		t.Fatalf("the nearest hop should win, got %s://%s", c.Scheme(), c.Host())
+	}
+}
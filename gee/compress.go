@@ -0,0 +1,244 @@
+package gee
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CompressWriter is a compressing stream that can be flushed mid-response
+type CompressWriter interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// Encoder produces one Content-Encoding, e.g. gzip, deflate or br
+type Encoder interface {
+	Encoding() string
+	NewWriter(w io.Writer) CompressWriter
+}
+
+type gzipEncoder struct{ level int }
+
+// GzipEncoder compresses with gzip at the given level, see compress/gzip
+func GzipEncoder(level int) Encoder {
+	if _, err := gzip.NewWriterLevel(io.Discard, level); err != nil {
+		panic(err)
+	}
+	return gzipEncoder{level}
+}
+
+func (e gzipEncoder) Encoding() string { return "gzip" }
+
+func (e gzipEncoder) NewWriter(w io.Writer) CompressWriter {
+	zw, _ := gzip.NewWriterLevel(w, e.level)
+	return zw
+}
+
+type deflateEncoder struct{ level int }
+
+// DeflateEncoder compresses with deflate at the given level, see compress/flate
+func DeflateEncoder(level int) Encoder {
+	if _, err := flate.NewWriter(io.Discard, level); err != nil {
+		panic(err)
+	}
+	return deflateEncoder{level}
+}
+
+func (e deflateEncoder) Encoding() string { return "deflate" }
+
+func (e deflateEncoder) NewWriter(w io.Writer) CompressWriter {
+	zw, _ := flate.NewWriter(w, e.level)
+	return zw
+}
+
+// CompressConfig configures the Compress middleware
+type CompressConfig struct {
+	// Encoders in order of preference, defaults to gzip then deflate
+	Encoders []Encoder
+	// MinLength is the smallest body worth compressing, defaults to 1024,
+	// a negative value compresses every body. Flushed responses are always
+	// compressed.
+	MinLength int
+	// ExcludedContentTypes are media type prefixes that are already
+	// compressed, nil uses the defaults and an empty slice excludes none
+	ExcludedContentTypes []string
+}
+
+// DefaultCompressConfig returns the config used by Compress
+func DefaultCompressConfig() CompressConfig {
+	return CompressConfig{
+		Encoders:  []Encoder{GzipEncoder(gzip.DefaultCompression), DeflateEncoder(flate.DefaultCompression)},
+		MinLength: 1024,
+		ExcludedContentTypes: []string{
+			"image/", "video/", "audio/", "font/woff",
+			"application/zip", "application/gzip", "application/x-gzip",
+			"application/zstd", "application/octet-stream", "application/pdf",
+		},
+	}
+}
+
+// Compress returns a middleware with DefaultCompressConfig
+func Compress() HandlerFunc {
+	return CompressWithConfig(DefaultCompressConfig())
+}
+
+// CompressWithConfig wraps c.Writer so every renderer's output is compressed
+// with the best encoding accepted by the client
+func CompressWithConfig(config CompressConfig) HandlerFunc {
+	defaults := DefaultCompressConfig()
+	if config.Encoders == nil {
+		config.Encoders = defaults.Encoders
+	}
+	if config.MinLength == 0 {
+		config.MinLength = defaults.MinLength
+	}
+	if config.ExcludedContentTypes == nil {
+		config.ExcludedContentTypes = defaults.ExcludedContentTypes
+	}
+	return func(c *Context) {
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		enc := negotiateEncoding(c.Req.Header.Get("Accept-Encoding"), config.Encoders)
+		if enc == nil || c.Method == http.MethodHead || c.Req.Header.Get("Range") != "" {
+			c.Next()
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: c.Writer, encoder: enc, config: &config}
+		c.Writer = cw
+		defer func() {
+			cw.finish()
+			c.Writer = cw.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// negotiateEncoding picks the accepted encoder with the highest q-value,
+// ties are broken by the server's preference
+func negotiateEncoding(header string, encoders []Encoder) Encoder {
+	var best Encoder
+	bestQ := 0.0
+	for _, enc := range encoders {
+		q, wildcard := -1.0, -1.0
+		for _, spec := range strings.Split(header, ",") {
+			name, params, _ := strings.Cut(strings.TrimSpace(spec), ";")
+			weight := 1.0
+			if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					weight = f
+				}
+			}
+			switch strings.ToLower(strings.TrimSpace(name)) {
+			case enc.Encoding():
+				q = weight
+			case "*":
+				wildcard = weight
+			}
+		}
+		if q < 0 {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+	return best
+}
+
+// compressWriter buffers the body until MinLength bytes are written, a
+// Flush is requested or the handler returns, then decides whether to compress
+type compressWriter struct {
+	http.ResponseWriter
+	encoder Encoder
+	config  *CompressConfig
+
+	status  int
+	buf     bytes.Buffer
+	decided bool
+	zw      CompressWriter
+}
+
+func (w *compressWriter) WriteHeader(code int) {
+	if w.decided || w.status != 0 {
+		return
+	}
+	w.status = code
+	// responses without a body are passed through untouched
+	if code < 200 || code == http.StatusNoContent || code == http.StatusNotModified {
+		w.decide(false)
+	}
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if !w.decided {
+		w.buf.Write(b)
+		if w.buf.Len() >= w.config.MinLength {
+			w.decide(true)
+		}
+		return len(b), nil
+	}
+	if w.zw != nil {
+		return w.zw.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(true)
+	}
+	if w.zw != nil {
+		w.zw.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *compressWriter) decide(compress bool) {
+	w.decided = true
+	header := w.Header()
+	if header.Get("Content-Type") == "" && w.buf.Len() > 0 {
+		// sniff before compressing, net/http would otherwise sniff compressed bytes
+		header.Set("Content-Type", http.DetectContentType(w.buf.Bytes()))
+	}
+	if compress && header.Get("Content-Encoding") == "" && !w.excluded(header.Get("Content-Type")) {
+		header.Set("Content-Encoding", w.encoder.Encoding())
+		header.Del("Content-Length")
+		w.zw = w.encoder.NewWriter(w.ResponseWriter)
+	}
+	if w.status != 0 {
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+	if w.buf.Len() > 0 {
+		if w.zw != nil {
+			w.zw.Write(w.buf.Bytes())
+		} else {
+			w.ResponseWriter.Write(w.buf.Bytes())
+		}
+		w.buf.Reset()
+	}
+}
+
+func (w *compressWriter) excluded(contentType string) bool {
+	for _, prefix := range w.config.ExcludedContentTypes {
+		if strings.HasPrefix(contentType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *compressWriter) finish() {
+	if !w.decided {
+		w.decide(false)
+	}
+	if w.zw != nil {
+		w.zw.Close()
+	}
+}
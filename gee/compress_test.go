@@ -0,0 +1,107 @@
+package gee
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	encoders := DefaultCompressConfig().Encoders
+	for header, want := range map[string]string{
+		"":                         "",
+		"gzip, deflate":            "gzip",
+		"deflate":                  "deflate",
+		"gzip;q=0.5, deflate":      "deflate",
+		"gzip;q=0, *":              "deflate",
+		"br":                       "",
+		"*;q=0":                    "",
+		"identity, GZIP;q=1.0":     "gzip",
+		"deflate;q=0.8, gzip;q=.8": "gzip",
+	} {
+		got := ""
+		if enc := negotiateEncoding(header, encoders); enc != nil {
+			got = enc.Encoding()
+		}
+		if got != want {
+			t.Fatalf("negotiateEncoding(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat("gee ", 1024)
+	r := New()
+	r.Use(Compress())
+	r.GET("/large", func(c *Context) { c.String(http.StatusOK, large) })
+	r.GET("/small", func(c *Context) { c.String(http.StatusOK, "tiny") })
+	r.GET("/png", func(c *Context) {
+		c.SetHeader("Content-Type", "image/png")
+		c.Data(http.StatusOK, []byte(large))
+	})
+	r.GET("/stream", func(c *Context) {
+		c.Writer.Write([]byte("chunk"))
+		c.Writer.(http.Flusher).Flush()
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		r.ServeHTTP(w, req)
+		return w
+	}
+	gunzip := func(w *httptest.ResponseRecorder) string {
+		zr, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	w := serve("/large")
+	if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Fatalf("large body should be gzipped, got headers %v", w.Header())
+	}
+	if w.Header().Get("Content-Type") != "text/plain" || gunzip(w) != large {
+		t.Fatal("gzipped body mismatch")
+	}
+
+	if w = serve("/small"); w.Header().Get("Content-Encoding") != "" || w.Body.String() != "tiny" {
+		t.Fatalf("small body should be sent as is, got %q", w.Body.String())
+	}
+	if w = serve("/png"); w.Header().Get("Content-Encoding") != "" || w.Body.Len() != len(large) {
+		t.Fatal("already compressed content types should be skipped")
+	}
+
+	w = serve("/stream")
+	if w.Header().Get("Content-Encoding") != "gzip" || !w.Flushed || gunzip(w) != "chunk" {
+		t.Fatal("flushed response should be compressed and flushed")
+	}
+}
+
+func TestCompressConfigDefaults(t *testing.T) {
+	r := New()
+	r.Use(CompressWithConfig(CompressConfig{Encoders: []Encoder{GzipEncoder(gzip.BestSpeed)}}))
+	r.GET("/small", func(c *Context) { c.String(http.StatusOK, "ok") })
+	r.GET("/image", func(c *Context) {
+		c.SetHeader("Content-Type", "image/png")
+		c.Data(http.StatusOK, make([]byte, 4096))
+	})
+	for _, path := range []string{"/small", "/image"} {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Header().Get("Content-Encoding") != "" {
+			t.Fatalf("%s should not be compressed with the default MinLength and exclusions", path)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package gee
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ETag sets the ETag of the response and evaluates the conditional
+// headers of the request against it. It returns true when it answered
+// 304 Not Modified or 412 Precondition Failed, the handler should then
+// return:
+//
+//	if c.ETag(version) {
+//		return
+//	}
+//
+// An unquoted value is quoted, "W/" marks a weak ETag.
+func (c *Context) ETag(etag string) bool {
+	if !strings.HasPrefix(etag, `"`) && !strings.HasPrefix(etag, `W/"`) {
+		etag = `"` + etag + `"`
+	}
+	c.SetHeader("ETag", etag)
+	return c.checkPreconditions()
+}
+
+// LastModified sets the Last-Modified time of the response and evaluates
+// the conditional headers of the request like ETag
+func (c *Context) LastModified(t time.Time) bool {
+	if !t.IsZero() {
+		c.SetHeader("Last-Modified", t.UTC().Format(http.TimeFormat))
+	}
+	return c.checkPreconditions()
+}
+
+// checkPreconditions follows RFC 9110 section 13.2.2 with the validators
+// set on the response so far. If-Match waits for an ETag, so when both
+// helpers are used LastModified goes first.
+func (c *Context) checkPreconditions() bool {
+	header := c.Req.Header
+	etag := c.Writer.Header().Get("ETag")
+	var lastModified time.Time
+	if v := c.Writer.Header().Get("Last-Modified"); v != "" {
+		lastModified, _ = http.ParseTime(v)
+	}
+
+	if ifMatch := header.Get("If-Match"); ifMatch != "" {
+		if etag != "" && !etagMatch(ifMatch, etag, false) {
+			return c.preconditionFailed()
+		}
+	} else if since, err := http.ParseTime(header.Get("If-Unmodified-Since")); err == nil && !lastModified.IsZero() {
+		if lastModified.After(since) {
+			return c.preconditionFailed()
+		}
+	}
+
+	safe := c.Method == http.MethodGet || c.Method == http.MethodHead
+	if ifNoneMatch := header.Get("If-None-Match"); ifNoneMatch != "" {
+		if etag != "" && etagMatch(ifNoneMatch, etag, true) {
+			if safe {
+				return c.notModified()
+			}
+			return c.preconditionFailed()
+		}
+	} else if since, err := http.ParseTime(header.Get("If-Modified-Since")); err == nil && safe && !lastModified.IsZero() {
+		if !lastModified.After(since) {
+			return c.notModified()
+		}
+	}
+	return false
+}
+
+func (c *Context) notModified() bool {
+	header := c.Writer.Header()
+	header.Del("Content-Type")
+	header.Del("Content-Length")
+	c.Abort()
+	c.Status(http.StatusNotModified)
+	return true
+}
+
+func (c *Context) preconditionFailed() bool {
+	c.Fail(http.StatusPreconditionFailed, "Precondition Failed")
+	return true
+}
+
+// AutoETag returns a middleware buffering GET and HEAD responses to give
+// successful ones without an ETag a weak ETag hashing their body, and to
+// answer matching If-None-Match with 304
+func AutoETag() HandlerFunc {
+	return func(c *Context) {
+		if c.Method != http.MethodGet && c.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+		writer := c.Writer
+		rec := nextRecorded(c)
+
+		if rec.statusCode() == http.StatusOK && rec.header.Get("ETag") == "" && rec.body.Len() > 0 {
+			h := fnv.New64a()
+			h.Write(rec.body.Bytes())
+			etag := fmt.Sprintf(`W/"%x-%x"`, rec.body.Len(), h.Sum64())
+			rec.header.Set("ETag", etag)
+			if etagMatch(c.Req.Header.Get("If-None-Match"), etag, true) {
+				header := writer.Header()
+				for k, v := range rec.header {
+					header[k] = v
+				}
+				c.notModified()
+				return
+			}
+		}
+		rec.writeTo(writer)
+	}
+}
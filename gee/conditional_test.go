@@ -0,0 +1,90 @@
+package gee
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestConditionalHelpers(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	r := New()
+	handler := func(c *Context) {
+		if c.LastModified(modified) || c.ETag("v2") {
+			return
+		}
+		c.String(http.StatusOK, "doc")
+	}
+	r.GET("/doc", handler)
+	r.PUT("/doc", handler)
+
+	tests := []struct {
+		method, header, value string
+		code                  int
+	}{
+		{"GET", "", "", http.StatusOK},
+		{"GET", "If-None-Match", `"v1", W/"v2"`, http.StatusNotModified},
+		{"GET", "If-None-Match", `"v1"`, http.StatusOK},
+		{"GET", "If-Modified-Since", modified.Format(http.TimeFormat), http.StatusNotModified},
+		{"GET", "If-Modified-Since", modified.Add(-time.Hour).Format(http.TimeFormat), http.StatusOK},
+		{"PUT", "If-Match", `"v2"`, http.StatusOK},
+		{"PUT", "If-Match", `"v1"`, http.StatusPreconditionFailed},
+		{"PUT", "If-None-Match", "*", http.StatusPreconditionFailed},
+		{"PUT", "If-Unmodified-Since", modified.Add(-time.Hour).Format(http.TimeFormat), http.StatusPreconditionFailed},
+		{"PUT", "If-Unmodified-Since", modified.Format(http.TimeFormat), http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/doc", nil)
+		if tt.header != "" {
+			req.Header.Set(tt.header, tt.value)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Fatalf("%s %s: %s = %d, want %d", tt.method, tt.header, tt.value, w.Code, tt.code)
+		}
+	}
+}
+
+func TestAutoETag(t *testing.T) {
+	r := New()
+	r.Use(AutoETag())
+	r.GET("/a", func(c *Context) { c.String(http.StatusOK, "hello") })
+	r.GET("/tagged", func(c *Context) {
+		c.SetHeader("ETag", `"mine"`)
+		c.String(http.StatusOK, "hello")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/a", nil))
+	etag := w.Header().Get("ETag")
+	if w.Body.String() != "hello" || len(etag) < 4 || etag[:3] != `W/"` {
+		t.Fatalf("got %q with ETag %q", w.Body.String(), etag)
+	}
+
+	req := httptest.NewRequest("GET", "/a", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 || w.Header().Get("ETag") != etag {
+		t.Fatalf("matching ETag should get 304, got %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/tagged", nil))
+	if w.Header().Get("ETag") != `"mine"` {
+		t.Fatalf("ETags set by handlers should be kept, got %q", w.Header().Get("ETag"))
+	}
+}
+
+func TestAutoETagPanic(t *testing.T) {
+	r := New()
+	r.Use(Recovery(), AutoETag())
+	r.GET("/p", func(c *Context) { panic("boom") })
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/p", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("panics should reach Recovery, got %d", w.Code)
+	}
+}
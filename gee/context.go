@@ -1,8 +1,11 @@
 package gee
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"log"
+	"math"
 	"net/http"
 )
 
@@ -18,6 +21,7 @@ type Context struct{
 	Path 		string
 	Method 		string
 	Params 		map[string]string
+	fullPath 	string
 	//response info
 	StatusCode 	int
 	//Middleware
@@ -25,6 +29,8 @@ type Context struct{
 	index 		int
 	//Static File
 	engine 		*Engine
+	//per-request values shared between middlewares
+	Keys 		map[string]interface{}
 }
 
 func newContext(w http.ResponseWriter, r *http.Request)*Context{
@@ -45,11 +51,41 @@ func (c *Context) Next(){
 	}
 }
 
+// abortIndex is past any realistic handler chain, so Next stops immediately
+const abortIndex = math.MaxInt16
+
+// Abort prevents the remaining handlers in the chain from being called
+func (c *Context) Abort() {
+	c.index = abortIndex
+}
+
+func (c *Context) IsAborted() bool {
+	return c.index >= abortIndex
+}
+
+// Set stores a value for this request only
+func (c *Context) Set(key string, value interface{}) {
+	if c.Keys == nil {
+		c.Keys = make(map[string]interface{})
+	}
+	c.Keys[key] = value
+}
+
+func (c *Context) Get(key string) (value interface{}, ok bool) {
+	value, ok = c.Keys[key]
+	return
+}
+
 func (c *Context) Param(key string) string {
 	value, _ := c.Params[key]
 	return value
 }
 
+// FullPath returns the matched route pattern, e.g. "/user/:id", or "" when no route matched
+func (c *Context) FullPath() string {
+	return c.fullPath
+}
+
 func(c *Context) PostForm(key string) string{
 	return c.Req.FormValue(key)
 }
@@ -82,20 +118,74 @@ func (c *Context) JSON(code int, obj interface{}) {
 	}
 }
 
+// Redirect replies with a redirect to location, code should be 3xx
+func (c *Context) Redirect(code int, location string) {
+	c.StatusCode = code
+	http.Redirect(c.Writer, c.Req, location, code)
+}
+
 func (c *Context) Data(code int, data []byte) {
 	c.Status(code)
 	c.Writer.Write(data)
 }
 
+// templateKeys are copied from Keys into H template data, e.g. {{.csrfToken}}
+var templateKeys = []string{CSPNonceKey, CSRFTokenKey}
+
+func (c *Context) templateData(data interface{}) interface{} {
+	h, ok := data.(H)
+	if !ok {
+		return data
+	}
+	var merged H
+	for _, key := range templateKeys {
+		value, found := c.Keys[key]
+		if _, set := h[key]; !found || set {
+			continue
+		}
+		if merged == nil {
+			merged = make(H, len(h)+len(templateKeys))
+			for k, v := range h {
+				merged[k] = v
+			}
+		}
+		merged[key] = value
+	}
+	if merged == nil {
+		return data
+	}
+	return merged
+}
+
+// HTML renders with the engine's HTMLRender into a buffer first, so a
+// failing template yields a 500 instead of a partial page
 func (c *Context) HTML(code int, html string,data interface{}) {
+	c.render(c.engine.htmlRender, code, html, data)
+}
+
+// HTMLWith renders with the HTMLRender registered under render
+func (c *Context) HTMLWith(render string, code int, html string, data interface{}) {
+	c.render(c.engine.htmlRenders[render], code, html, data)
+}
+
+func (c *Context) render(r HTMLRender, code int, html string, data interface{}) {
+	data = c.templateData(data)
+	var buf bytes.Buffer
+	err := fmt.Errorf("gee: no HTML render configured")
+	if r != nil {
+		err = r.Render(&buf, html, data)
+	}
+	if err != nil {
+		log.Printf("render %s: %v", html, err)
+		c.Fail(http.StatusInternalServerError, "Internal Server Error")
+		return
+	}
 	c.SetHeader("Content-Type", "text/html")
 	c.Status(code)
-	if err:=c.engine.htmlTemplates.ExecuteTemplate(c.Writer,html,data);err!=nil{
-		c.Writer.Write([]byte(html))
-	}
+	c.Writer.Write(buf.Bytes())
 }
 
 func (c *Context) Fail(code int, err string) {
-	c.index=len(c.handlers)
+	c.Abort()
 	c.JSON(code,H{"message":err})
 }
\ No newline at end of file
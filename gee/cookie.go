@@ -0,0 +1,192 @@
+package gee
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// CookieOptions are the attributes of a cookie set by SetCookie
+type CookieOptions struct {
+	// Path defaults to "/"
+	Path   string
+	Domain string
+	// MaxAge <0 deletes the cookie, 0 makes it a session cookie
+	MaxAge   int
+	Secure   bool
+	HttpOnly bool
+	SameSite http.SameSite
+}
+
+// Cookie returns the unescaped value of the named request cookie
+func (c *Context) Cookie(name string) (string, error) {
+	cookie, err := c.Req.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	return url.QueryUnescape(cookie.Value)
+}
+
+// SetCookie adds a Set-Cookie header, the value is escaped
+func (c *Context) SetCookie(name, value string, opts CookieOptions) {
+	if opts.Path == "" {
+		opts.Path = "/"
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    url.QueryEscape(value),
+		Path:     opts.Path,
+		Domain:   opts.Domain,
+		MaxAge:   opts.MaxAge,
+		Secure:   opts.Secure,
+		HttpOnly: opts.HttpOnly,
+		SameSite: opts.SameSite,
+	})
+}
+
+// CookieKey signs cookies with HashKey and, when BlockKey is set, encrypts
+// them with AES (16, 24 or 32 byte keys for AES-128/192/256)
+type CookieKey struct {
+	HashKey  []byte
+	BlockKey []byte
+}
+
+var (
+	ErrCookieInvalid = errors.New("cookie value is invalid")
+	ErrCookieExpired = errors.New("cookie value is expired")
+	errNoCookieKeys  = errors.New("no cookie keys configured, see Engine.SetCookieKeys")
+)
+
+// SecureCookie encodes cookie values so clients can neither read (with a
+// BlockKey) nor forge them. The first key encodes, every key decodes, so
+// keys can be rotated by prepending the new one.
+type SecureCookie struct {
+	keys []CookieKey
+	// MaxAge rejects values older than it, 0 disables the check
+	MaxAge time.Duration
+}
+
+func NewSecureCookie(keys ...CookieKey) *SecureCookie {
+	if len(keys) == 0 {
+		panic("gee: SecureCookie requires a key")
+	}
+	for _, key := range keys {
+		if len(key.HashKey) == 0 {
+			panic("gee: CookieKey requires a HashKey")
+		}
+		if key.BlockKey != nil {
+			if _, err := aes.NewCipher(key.BlockKey); err != nil {
+				panic(err)
+			}
+		}
+	}
+	return &SecureCookie{keys: keys}
+}
+
+// Encode returns base64(timestamp | payload | hmac), the name is
+// authenticated too so a value can not be moved to another cookie
+func (s *SecureCookie) Encode(name string, value []byte) (string, error) {
+	key := s.keys[0]
+	payload := value
+	if key.BlockKey != nil {
+		gcm, _ := newGCM(key.BlockKey)
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return "", err
+		}
+		payload = gcm.Seal(nonce, nonce, value, []byte(name))
+	}
+	data := make([]byte, 8, 8+len(payload)+sha256.Size)
+	binary.BigEndian.PutUint64(data, uint64(time.Now().Unix()))
+	data = append(data, payload...)
+	data = append(data, cookieMAC(key.HashKey, name, data)...)
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// Decode verifies and decrypts a value produced by Encode with any key
+func (s *SecureCookie) Decode(name, encoded string) ([]byte, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(data) < 8+sha256.Size {
+		return nil, ErrCookieInvalid
+	}
+	signed, mac := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+	for _, key := range s.keys {
+		if !hmac.Equal(mac, cookieMAC(key.HashKey, name, signed)) {
+			continue
+		}
+		issued := time.Unix(int64(binary.BigEndian.Uint64(signed)), 0)
+		if s.MaxAge > 0 && time.Since(issued) > s.MaxAge {
+			return nil, ErrCookieExpired
+		}
+		payload := signed[8:]
+		if key.BlockKey == nil {
+			return append([]byte(nil), payload...), nil
+		}
+		gcm, _ := newGCM(key.BlockKey)
+		if len(payload) < gcm.NonceSize() {
+			return nil, ErrCookieInvalid
+		}
+		value, err := gcm.Open(nil, payload[:gcm.NonceSize()], payload[gcm.NonceSize():], []byte(name))
+		if err != nil {
+			return nil, ErrCookieInvalid
+		}
+		return value, nil
+	}
+	return nil, ErrCookieInvalid
+}
+
+func cookieMAC(key []byte, name string, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(name))
+	mac.Write([]byte{'|'})
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SetCookieKeys configures the keys used by SetSecureCookie and SecureCookie,
+// the first key is used to encode
+func (engine *Engine) SetCookieKeys(keys ...CookieKey) {
+	engine.cookies = NewSecureCookie(keys...)
+}
+
+// SetSecureCookie sets a cookie signed, and possibly encrypted, with the engine's keys
+func (c *Context) SetSecureCookie(name, value string, opts CookieOptions) error {
+	if c.engine.cookies == nil {
+		return errNoCookieKeys
+	}
+	encoded, err := c.engine.cookies.Encode(name, []byte(value))
+	if err != nil {
+		return err
+	}
+	c.SetCookie(name, encoded, opts)
+	return nil
+}
+
+// SecureCookie returns the verified value of a cookie set by SetSecureCookie
+func (c *Context) SecureCookie(name string) (string, error) {
+	if c.engine.cookies == nil {
+		return "", errNoCookieKeys
+	}
+	encoded, err := c.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+	value, err := c.engine.cookies.Decode(name, encoded)
+	return string(value), err
+}
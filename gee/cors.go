@@ -0,0 +1,142 @@
+package gee
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSConfig configures the CORS middleware
+type CORSConfig struct {
+	// AllowOrigins is a list of exact origins ("https://a.com"),
+	// wildcard origins ("https://*.a.com") or "*" for any origin
+	AllowOrigins []string
+	// AllowOriginFunc is consulted when no entry of AllowOrigins matches
+	AllowOriginFunc  func(origin string) bool
+	AllowMethods     []string
+	AllowHeaders     []string
+	ExposeHeaders    []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// DefaultCORSConfig allows any origin with the common methods
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowOrigins: []string{"*"},
+		AllowMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD"},
+		AllowHeaders: []string{"Origin", "Content-Type", "Accept", "Authorization"},
+		MaxAge:       12 * time.Hour,
+	}
+}
+
+// CORS returns a middleware with DefaultCORSConfig
+func CORS() HandlerFunc {
+	return CORSWithConfig(DefaultCORSConfig())
+}
+
+// CORSWithConfig returns a middleware answering preflight requests and
+// decorating actual requests with the Access-Control-* headers.
+//
+// A preflight (OPTIONS with Access-Control-Request-Method) is answered with
+// 204 when a route exists for the requested method. If an OPTIONS route is
+// registered for the path, it runs after the headers are set instead.
+func CORSWithConfig(config CORSConfig) HandlerFunc {
+	allowMethods := strings.Join(config.AllowMethods, ", ")
+	allowHeaders := strings.Join(config.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(config.ExposeHeaders, ", ")
+	maxAge := ""
+	if config.MaxAge > 0 {
+		maxAge = strconv.FormatInt(int64(config.MaxAge/time.Second), 10)
+	}
+	allowAll := false
+	for _, o := range config.AllowOrigins {
+		if o == "*" {
+			allowAll = true
+		}
+	}
+
+	return func(c *Context) {
+		origin := c.Req.Header.Get("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		c.Writer.Header().Add("Vary", "Origin")
+		preflight := c.Method == http.MethodOptions && c.Req.Header.Get("Access-Control-Request-Method") != ""
+
+		if !config.allowOrigin(origin) {
+			if preflight {
+				c.Status(http.StatusForbidden)
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if allowAll && !config.AllowCredentials {
+			c.SetHeader("Access-Control-Allow-Origin", "*")
+		} else {
+			c.SetHeader("Access-Control-Allow-Origin", origin)
+		}
+		if config.AllowCredentials {
+			c.SetHeader("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if exposeHeaders != "" {
+				c.SetHeader("Access-Control-Expose-Headers", exposeHeaders)
+			}
+			c.Next()
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+		if allowMethods != "" {
+			c.SetHeader("Access-Control-Allow-Methods", allowMethods)
+		}
+		if allowHeaders != "" {
+			c.SetHeader("Access-Control-Allow-Headers", allowHeaders)
+		} else if h := c.Req.Header.Get("Access-Control-Request-Headers"); h != "" {
+			c.SetHeader("Access-Control-Allow-Headers", h)
+		}
+		if maxAge != "" {
+			c.SetHeader("Access-Control-Max-Age", maxAge)
+		}
+
+		router := c.engine.router
+		if _, n, _ := router.getHostRoute(http.MethodOptions, c.Req.Host, c.Path); n != nil {
+			c.Next()
+			return
+		}
+		if _, n, _ := router.getHostRoute(c.Req.Header.Get("Access-Control-Request-Method"), c.Req.Host, c.Path); n == nil {
+			c.Next()
+			return
+		}
+		c.Status(http.StatusNoContent)
+		c.Abort()
+	}
+}
+
+func (config *CORSConfig) allowOrigin(origin string) bool {
+	for _, o := range config.AllowOrigins {
+		if o == "*" || o == origin {
+			return true
+		}
+		if i := strings.IndexByte(o, '*'); i >= 0 {
+			prefix, suffix := o[:i], o[i+1:]
+			if len(origin) > len(prefix)+len(suffix) &&
+				strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+				return true
+			}
+		}
+	}
+	if config.AllowOriginFunc != nil {
+		return config.AllowOriginFunc(origin)
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package gee
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newCORSEngine(config CORSConfig) *Engine {
+	r := New()
+	r.Use(CORSWithConfig(config))
+	r.GET("/users/:id", func(c *Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	return r
+}
+
+func TestCORSAllowOrigin(t *testing.T) {
+	config := CORSConfig{AllowOrigins: []string{"https://a.com", "https://*.b.com"}}
+	for origin, want := range map[string]bool{
+		"https://a.com":     true,
+		"https://x.b.com":   true,
+		"https://b.com":     false,
+		"https://evil.com":  false,
+		"http://x.b.com":    false,
+		"https://a.com.cn":  false,
+		"https://x.y.b.com": true,
+	} {
+		if got := config.allowOrigin(origin); got != want {
+			t.Fatalf("allowOrigin(%s) = %t, want %t", origin, got, want)
+		}
+	}
+	config.AllowOriginFunc = func(origin string) bool { return origin == "https://evil.com" }
+	if !config.allowOrigin("https://evil.com") {
+		t.Fatal("AllowOriginFunc should be consulted")
+	}
+}
+
+func TestCORSSimpleRequest(t *testing.T) {
+	config := DefaultCORSConfig()
+	config.ExposeHeaders = []string{"X-Total"}
+	r := newCORSEngine(config)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/users/1", nil)
+	req.Header.Set("Origin", "https://a.com")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Body.String() != "ok" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Fatalf("Access-Control-Allow-Origin = %q", got)
+	}
+	if got := w.Header().Get("Access-Control-Expose-Headers"); got != "X-Total" {
+		t.Fatalf("Access-Control-Expose-Headers = %q", got)
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	config := DefaultCORSConfig()
+	config.AllowOrigins = []string{"https://a.com"}
+	config.AllowCredentials = true
+	r := newCORSEngine(config)
+
+	preflight := func(origin, method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("OPTIONS", path, nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", method)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := preflight("https://a.com", "GET", "/users/1")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("preflight status = %d", w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://a.com" {
+		t.Fatalf("credentials must echo the origin, got %q", got)
+	}
+	if w.Header().Get("Access-Control-Allow-Credentials") != "true" || w.Header().Get("Access-Control-Max-Age") != "43200" {
+		t.Fatalf("unexpected preflight headers %v", w.Header())
+	}
+
+	if w = preflight("https://a.com", "GET", "/missing"); w.Code != http.StatusNotFound {
+		t.Fatalf("preflight for unknown route should fall through to 404, got %d", w.Code)
+	}
+	if w = preflight("https://evil.com", "GET", "/users/1"); w.Code != http.StatusForbidden {
+		t.Fatalf("preflight from disallowed origin should be 403, got %d", w.Code)
+	}
+
+	r.OPTIONS("/users/:id", func(c *Context) {
+		c.String(http.StatusOK, "custom")
+	})
+	if w = preflight("https://a.com", "GET", "/users/1"); w.Body.String() != "custom" {
+		t.Fatalf("explicit OPTIONS route should handle preflight, got %q", w.Body.String())
+	}
+}
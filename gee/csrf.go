@@ -0,0 +1,106 @@
+package gee
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"time"
+)
+
+// CSRFTokenKey is the context key, and the template data key, of the CSRF token
+const CSRFTokenKey = "csrfToken"
+
+// CSRFConfig configures the CSRF middleware
+type CSRFConfig struct {
+	// CookieName defaults to "_csrf"
+	CookieName string
+	// HeaderName defaults to "X-CSRF-Token"
+	HeaderName string
+	// FormField defaults to "_csrf"
+	FormField string
+	// CookiePath defaults to "/"
+	CookiePath   string
+	CookieDomain string
+	CookieSecure bool
+	// CookieHTTPOnly hides the cookie from scripts. It is off by default
+	// so JS clients can read the token and send it in HeaderName; set it
+	// when the token is only rendered into templates.
+	CookieHTTPOnly bool
+	// SameSite defaults to http.SameSiteLaxMode
+	SameSite http.SameSite
+	// MaxAge defaults to 12 hours
+	MaxAge time.Duration
+	// ErrorHandler answers rejected requests, defaults to 403
+	ErrorHandler HandlerFunc
+}
+
+// CSRF returns a middleware with the default CSRFConfig
+func CSRF() HandlerFunc {
+	return CSRFWithConfig(CSRFConfig{})
+}
+
+// CSRFWithConfig implements the double submit cookie pattern: a random
+// token is issued in a cookie and unsafe methods must echo it in the
+// header or form field. The token is stored under CSRFTokenKey and added
+// to the data of c.HTML so forms can embed it:
+//
+//	<input type="hidden" name="_csrf" value="{{.csrfToken}}">
+func CSRFWithConfig(config CSRFConfig) HandlerFunc {
+	if config.CookieName == "" {
+		config.CookieName = "_csrf"
+	}
+	if config.HeaderName == "" {
+		config.HeaderName = "X-CSRF-Token"
+	}
+	if config.FormField == "" {
+		config.FormField = "_csrf"
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = "/"
+	}
+	if config.SameSite == 0 {
+		config.SameSite = http.SameSiteLaxMode
+	}
+	if config.MaxAge == 0 {
+		config.MaxAge = 12 * time.Hour
+	}
+	if config.ErrorHandler == nil {
+		config.ErrorHandler = func(c *Context) {
+			c.Fail(http.StatusForbidden, "invalid csrf token")
+		}
+	}
+
+	return func(c *Context) {
+		token := ""
+		if value, err := c.Cookie(config.CookieName); err == nil && len(value) == 43 {
+			token = value
+		}
+		if token == "" {
+			token = randomBase64(32)
+			c.SetCookie(config.CookieName, token, CookieOptions{
+				Path:     config.CookiePath,
+				Domain:   config.CookieDomain,
+				MaxAge:   int(config.MaxAge / time.Second),
+				Secure:   config.CookieSecure,
+				HttpOnly: config.CookieHTTPOnly,
+				SameSite: config.SameSite,
+			})
+		}
+		c.Set(CSRFTokenKey, token)
+
+		switch c.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+			c.Next()
+			return
+		}
+		sent := c.Req.Header.Get(config.HeaderName)
+		if sent == "" {
+			sent = c.PostForm(config.FormField)
+		}
+		if sent == "" || subtle.ConstantTimeCompare([]byte(sent), []byte(token)) != 1 {
+			c.Abort()
+			config.ErrorHandler(c)
+			return
+		}
+		c.Next()
+	}
+}
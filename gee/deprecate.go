@@ -0,0 +1,108 @@
+package gee
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DeprecationConfig describes a deprecated group or route
+type DeprecationConfig struct {
+	// Date is when it was deprecated, sent as the Deprecation header
+	// (RFC 9745). Zero sends "true".
+	Date time.Time
+	// Sunset is when it stops being served, sent as the Sunset header
+	// (RFC 8594). Zero sends none.
+	Sunset time.Time
+	// Link points to the migration guide, sent as a Link with rel="deprecation"
+	Link string
+	// GoneAfterSunset answers 410 Gone once Sunset has passed
+	GoneAfterSunset bool
+}
+
+// deprecationUsage counts the requests served by deprecated routes
+type deprecationUsage struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+// Deprecate marks every route of the group deprecated
+func (group *RouterGroup) Deprecate(config DeprecationConfig) *RouterGroup {
+	group.Use(Deprecated(config))
+	return group
+}
+
+// Deprecated returns a middleware marking the routes it runs for
+// deprecated, usable with Use or wrapping a single route handler with
+// Chain. Requests are counted in engine.DeprecatedUsage.
+func Deprecated(config DeprecationConfig) HandlerFunc {
+	deprecation := "true"
+	if !config.Date.IsZero() {
+		deprecation = fmt.Sprintf("@%d", config.Date.Unix())
+	}
+	var sunset string
+	if !config.Sunset.IsZero() {
+		sunset = config.Sunset.UTC().Format(http.TimeFormat)
+	}
+	return func(c *Context) {
+		header := c.Writer.Header()
+		header.Set("Deprecation", deprecation)
+		if sunset != "" {
+			header.Set("Sunset", sunset)
+		}
+		if config.Link != "" {
+			header.Add("Link", fmt.Sprintf(`<%s>; rel="deprecation"`, config.Link))
+		}
+		if c.engine != nil && c.FullPath() != "" {
+			c.engine.deprecations.add(c.Method + " " + c.FullPath())
+		}
+		if config.GoneAfterSunset && sunset != "" && !time.Now().Before(config.Sunset) {
+			c.Fail(http.StatusGone, "Gone")
+			return
+		}
+		c.Next()
+	}
+}
+
+// Chain returns a handler running middlewares before handler, so route
+// level middlewares can be given to the single handler route methods, e.g.
+// r.GET("/old", gee.Chain(handler, gee.Deprecated(config)))
+func Chain(handler HandlerFunc, middlewares ...HandlerFunc) HandlerFunc {
+	return func(c *Context) {
+		handlers, index := c.handlers, c.index
+		c.handlers = append(append([]HandlerFunc{}, middlewares...), handler)
+		c.index = -1
+		c.Next()
+		aborted := c.IsAborted()
+		c.handlers, c.index = handlers, index
+		if aborted {
+			c.Abort()
+		}
+	}
+}
+
+// DeprecatedUsage returns how many requests each deprecated route served,
+// keyed by "METHOD pattern"
+func (engine *Engine) DeprecatedUsage() map[string]int64 {
+	return engine.deprecations.snapshot()
+}
+
+func (u *deprecationUsage) add(route string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.counts == nil {
+		u.counts = make(map[string]int64)
+	}
+	u.counts[route]++
+}
+
+func (u *deprecationUsage) snapshot() map[string]int64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	counts := make(map[string]int64, len(u.counts))
+	for route, n := range u.counts {
+		counts[route] = n
+	}
+	return counts
+}
@@ -2,8 +2,8 @@ package gee
 
 import (
 	"html/template"
-	"path"
-	"log"
+	"io/fs"
+	"net"
 	"net/http"
 	"strings"
 )
@@ -14,19 +14,33 @@ type(
 		prefix 		string
 		middleware 	[]HandlerFunc
 		parent     	*RouterGroup
+		vhost 		*virtualHost		// virtual host of the routes, nil for any
 		engine 		*Engine				// all groups share a Engine instance
 	}
 	Engine struct{
 		*RouterGroup
 		router *router
 		groups []*RouterGroup
-		htmlTemplates *template.Template // for html render
-		funcMap       template.FuncMap   // for html render
+		htmlRender    HTMLRender            // for html render
+		htmlRenders   map[string]HTMLRender // selected per call by name
+		funcMap       template.FuncMap      // for html render
+		cookies       *SecureCookie      // for signed cookies
+		trustedProxies []*net.IPNet      // peers allowed to set forwarded headers
+		// RedirectTrailingSlash redirects /foo/ to the route /foo and the
+		// other way round, enabled by default. Disabled, both are served.
+		RedirectTrailingSlash bool
+		// RedirectFixedPath redirects paths with empty or dot segments and
+		// paths matching a route only case-insensitively to the route
+		RedirectFixedPath bool
+		versions       *VersionConfig   // Accept header versioning
+		deprecations   deprecationUsage // requests served by deprecated routes
+		routes         []RouteInfo            // registered routes, in order
+		docs           map[string]RouteDoc    // OpenAPI metadata by "METHOD pattern"
 	}
 )
 
 func New() *Engine {
-	engine:=&Engine{router: newRouter()}
+	engine:=&Engine{router: newRouter(), RedirectTrailingSlash: true}
 	engine.RouterGroup=&RouterGroup{engine:engine}
 	engine.groups= []*RouterGroup{engine.RouterGroup}
 
@@ -34,6 +48,7 @@ func New() *Engine {
 }
 
 func Default() *Engine{
+	debugPrintWarning(`Running in "debug" mode. Switch to "release" mode in production with gee.SetMode("release") or %s=release.`, EnvGeeMode)
 	engine := New()
 	engine.Use(Logger(),Recovery())//global middleware
 	return engine
@@ -44,6 +59,7 @@ func (group *RouterGroup)Group(prefix string) *RouterGroup {
 	newGroup:=&RouterGroup{
 		prefix:group.prefix+prefix,
 		parent:group,
+		vhost:group.vhost,
 		engine:engine,
 	}
 	engine.groups=append(engine.groups,newGroup)
@@ -52,8 +68,13 @@ func (group *RouterGroup)Group(prefix string) *RouterGroup {
 
 func (group *RouterGroup) addRoute(method string, comp string, handler HandlerFunc) {
 	pattern:=group.prefix+comp
-	log.Printf("Route %4s - %s", method, pattern)
-	group.engine.router.addRoute(method,pattern,handler)
+	if group.vhost!=nil{
+		debugPrint("Route %-7s %s%s", method, group.vhost.pattern, pattern)
+		group.vhost.router.addRoute(method,pattern,handler)
+		group.engine.routes=append(group.engine.routes,RouteInfo{Method:method,Path:pattern,Host:group.vhost.pattern})
+		return
+	}
+	group.engine.addRoute(method,pattern,handler)
 }
 
 // GET defines the method to add GET request
@@ -66,9 +87,57 @@ func (group *RouterGroup) POST(pattern string, handler HandlerFunc) {
 	group.addRoute("POST", pattern, handler)
 }
 
+// PUT defines the method to add PUT request
+func (group *RouterGroup) PUT(pattern string, handler HandlerFunc) {
+	group.addRoute("PUT", pattern, handler)
+}
+
+// PATCH defines the method to add PATCH request
+func (group *RouterGroup) PATCH(pattern string, handler HandlerFunc) {
+	group.addRoute("PATCH", pattern, handler)
+}
+
+// DELETE defines the method to add DELETE request
+func (group *RouterGroup) DELETE(pattern string, handler HandlerFunc) {
+	group.addRoute("DELETE", pattern, handler)
+}
+
+// HEAD defines the method to add HEAD request
+func (group *RouterGroup) HEAD(pattern string, handler HandlerFunc) {
+	group.addRoute("HEAD", pattern, handler)
+}
+
+// OPTIONS defines the method to add OPTIONS request
+func (group *RouterGroup) OPTIONS(pattern string, handler HandlerFunc) {
+	group.addRoute("OPTIONS", pattern, handler)
+}
+
+// anyMethods are the methods registered by Any
+var anyMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS", "CONNECT", "TRACE"}
+
+// Any registers the handler for every HTTP method
+func (group *RouterGroup) Any(pattern string, handler HandlerFunc) {
+	for _, method := range anyMethods {
+		group.addRoute(method, pattern, handler)
+	}
+}
 
 func (engine *Engine) addRoute(method string, pattern string, handler HandlerFunc) {
+	debugPrint("Route %-7s %s", method, pattern)
 	engine.router.addRoute(method,pattern,handler)
+	engine.routes=append(engine.routes,RouteInfo{Method:method,Path:pattern})
+}
+
+// RouteInfo describes a registered route
+type RouteInfo struct {
+	Method string
+	Path   string
+	Host   string // virtual host, "" for any
+}
+
+// Routes returns the registered routes in registration order
+func (engine *Engine) Routes() []RouteInfo {
+	return append([]RouteInfo(nil), engine.routes...)
 }
 
 // GET defines the method to add GET request
@@ -83,57 +152,84 @@ func (engine *Engine) POST(pattern string, handler HandlerFunc) {
 
 // Run defines the method to start a http server
 func (engine *Engine) Run(addr string) (err error) {
+	debugPrint("Listening and serving HTTP on %s", addr)
 	return http.ListenAndServe(addr, engine)
 }
 
 func (engine *Engine) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	path,version,acceptable:=req.URL.Path,"",true
+	var rt *router
+	var n *node
+	var params map[string]string
+	if engine.versions!=nil{
+		var versioned string
+		versioned,version,acceptable=engine.versions.versionPath(req)
+		if versioned!=path{
+			rt,n,params=engine.router.getHostRoute(req.Method,req.Host,versioned)
+			if n!=nil{
+				path=versioned
+			}
+		}
+	}
+	if n==nil{
+		// routes without a version, e.g. /health, are served as they are
+		rt,n,params=engine.router.getHostRoute(req.Method,req.Host,path)
+		if n!=nil{
+			version,acceptable="",true
+		}
+	}
 	middlewares:=[]HandlerFunc{}
 	for _,group:=range engine.groups{
-		if strings.HasPrefix(req.URL.Path,group.prefix){
+		// only the virtual host serving the route runs its middlewares
+		if group.vhost!=nil&&group.vhost.router!=rt{
+			continue
+		}
+		if strings.HasPrefix(path,group.prefix){
 			middlewares=append(middlewares,group.middleware...)
 		}
 	}
 
 	c:=newContext(w,req)
+	c.Path=path
 	c.handlers=middlewares
 	c.engine=engine
-	engine.router.handle(c)
-}
-
-func (group *RouterGroup) Use(middlewares ...HandlerFunc){
-	group.middleware=append(group.middleware,middlewares...)
-}
-
-
-// create static handler
-func (group *RouterGroup) createStaticHandler(relativePath string, fs http.FileSystem) HandlerFunc {
-	absolutePath := path.Join(group.prefix, relativePath)
-	fileServer := http.StripPrefix(absolutePath, http.FileServer(fs))
-	return func(c *Context) {
-		file := c.Param("filepath")
-		// Check if file exists and/or if we have permission to access it
-		if _, err := fs.Open(file); err != nil {
-			c.Status(http.StatusNotFound)
+	if engine.versions!=nil{
+		w.Header().Add("Vary","Accept")
+		if version!=""{
+			c.Set(APIVersionKey,version)
+		}
+		if !acceptable{
+			c.handlers=append(c.handlers,func(c *Context){
+				c.Fail(http.StatusNotAcceptable,"Not Acceptable")
+			})
+			c.Next()
 			return
 		}
-
-		fileServer.ServeHTTP(c.Writer, c.Req)
 	}
+	engine.router.handle(c,rt,n,params)
 }
 
-// serve static files
-func (group *RouterGroup) Static(relativePath string, root string) {
-	handler := group.createStaticHandler(relativePath, http.Dir(root))
-	urlPattern := path.Join(relativePath, "/*filepath")
-	// Register GET handlers
-	group.GET(urlPattern, handler)
+func (group *RouterGroup) Use(middlewares ...HandlerFunc){
+	group.middleware=append(group.middleware,middlewares...)
 }
 
+
+// SetFuncMap sets the funcs of LoadHTMLGlob/LoadHTMLFS and of every
+// HTMLRender accepting a FuncMap, call it before parsing templates
 func (engine *Engine) SetFuncMap(funcMap template.FuncMap){
 	engine.funcMap = funcMap
+	engine.applyFuncMap(engine.htmlRender)
+	for _, r := range engine.htmlRenders {
+		engine.applyFuncMap(r)
+	}
 }
 
 func (engine *Engine) LoadHTMLGlob(pattern string) {
-	engine.htmlTemplates = template.Must(template.New("").Funcs(engine.funcMap).ParseGlob(pattern))
+	engine.htmlRender = &TemplateRender{Template: template.Must(template.New("").Funcs(engine.funcMap).ParseGlob(pattern))}
+}
+
+// LoadHTMLFS parses the templates of fsys matching patterns, e.g. from an embed.FS
+func (engine *Engine) LoadHTMLFS(fsys fs.FS, patterns ...string) {
+	engine.htmlRender = &TemplateRender{Template: template.Must(template.New("").Funcs(engine.funcMap).ParseFS(fsys, patterns...))}
 }
 
@@ -0,0 +1,238 @@
+// Package geetest provides helpers to test gee handlers and middlewares:
+//
+//	client := geetest.New(t, r)
+//	client.POST("/users").JSON(gee.H{"name": "tom"}).Do().
+//		Status(http.StatusCreated).
+//		JSONPath("user.name", "tom")
+package geetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gee"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// CreateTestContext returns a Context for req whose chain is handlers and
+// the recorder it writes to. Call c.Next() to run the chain.
+func CreateTestContext(req *http.Request, handlers ...gee.HandlerFunc) (*gee.Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	c, _ := gee.CreateTestContext(w, req, handlers...)
+	return c, w
+}
+
+// Client sends requests to a handler in process
+type Client struct {
+	t       testing.TB
+	handler http.Handler
+	header  http.Header
+}
+
+// New returns a Client for handler, usually a *gee.Engine
+func New(t testing.TB, handler http.Handler) *Client {
+	return &Client{t: t, handler: handler, header: make(http.Header)}
+}
+
+// SetHeader sets a header sent with every request of the client
+func (c *Client) SetHeader(key, value string) *Client {
+	c.header.Set(key, value)
+	return c
+}
+
+func (c *Client) GET(path string) *Request    { return c.Request(http.MethodGet, path) }
+func (c *Client) POST(path string) *Request   { return c.Request(http.MethodPost, path) }
+func (c *Client) PUT(path string) *Request    { return c.Request(http.MethodPut, path) }
+func (c *Client) PATCH(path string) *Request  { return c.Request(http.MethodPatch, path) }
+func (c *Client) DELETE(path string) *Request { return c.Request(http.MethodDelete, path) }
+
+// Request starts building a request, send it with Do
+func (c *Client) Request(method, path string) *Request {
+	return &Request{client: c, method: method, path: path, header: c.header.Clone(), query: url.Values{}}
+}
+
+// Request is a request being built
+type Request struct {
+	client *Client
+	method string
+	path   string
+	header http.Header
+	query  url.Values
+	body   io.Reader
+	remote string
+}
+
+func (r *Request) Header(key, value string) *Request {
+	r.header.Set(key, value)
+	return r
+}
+
+func (r *Request) Query(key, value string) *Request {
+	r.query.Add(key, value)
+	return r
+}
+
+// RemoteAddr sets the address of the peer, e.g. "10.0.0.1:1234"
+func (r *Request) RemoteAddr(addr string) *Request {
+	r.remote = addr
+	return r
+}
+
+func (r *Request) Body(body string) *Request {
+	r.body = strings.NewReader(body)
+	return r
+}
+
+// JSON encodes v as the body
+func (r *Request) JSON(v interface{}) *Request {
+	b, err := json.Marshal(v)
+	if err != nil {
+		r.client.t.Fatalf("geetest: encoding JSON body: %v", err)
+	}
+	r.body = bytes.NewReader(b)
+	r.header.Set("Content-Type", "application/json")
+	return r
+}
+
+// Form encodes values as an urlencoded form body
+func (r *Request) Form(values url.Values) *Request {
+	r.body = strings.NewReader(values.Encode())
+	r.header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+// Do sends the request and returns the recorded response
+func (r *Request) Do() *Response {
+	target := r.path
+	if len(r.query) > 0 {
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		target += sep + r.query.Encode()
+	}
+	req := httptest.NewRequest(r.method, target, r.body)
+	req.Header = r.header
+	if r.remote != "" {
+		req.RemoteAddr = r.remote
+	}
+	w := httptest.NewRecorder()
+	r.client.handler.ServeHTTP(w, req)
+	return &Response{t: r.client.t, Recorder: w}
+}
+
+// Response holds a recorded response, its assertion methods report
+// failures with t.Errorf and can be chained
+type Response struct {
+	t        testing.TB
+	Recorder *httptest.ResponseRecorder
+	json     interface{}
+	decoded  bool
+}
+
+func (r *Response) Code() int {
+	return r.Recorder.Code
+}
+
+func (r *Response) BodyString() string {
+	return r.Recorder.Body.String()
+}
+
+// DecodeJSON decodes the body into v
+func (r *Response) DecodeJSON(v interface{}) error {
+	return json.Unmarshal(r.Recorder.Body.Bytes(), v)
+}
+
+func (r *Response) Status(code int) *Response {
+	r.t.Helper()
+	if r.Recorder.Code != code {
+		r.t.Errorf("geetest: status = %d, want %d, body: %s", r.Recorder.Code, code, r.BodyString())
+	}
+	return r
+}
+
+func (r *Response) Header(key, value string) *Response {
+	r.t.Helper()
+	if got := r.Recorder.Header().Get(key); got != value {
+		r.t.Errorf("geetest: header %s = %q, want %q", key, got, value)
+	}
+	return r
+}
+
+func (r *Response) Body(body string) *Response {
+	r.t.Helper()
+	if got := r.BodyString(); got != body {
+		r.t.Errorf("geetest: body = %q, want %q", got, body)
+	}
+	return r
+}
+
+func (r *Response) BodyContains(sub string) *Response {
+	r.t.Helper()
+	if got := r.BodyString(); !strings.Contains(got, sub) {
+		r.t.Errorf("geetest: body %q does not contain %q", got, sub)
+	}
+	return r
+}
+
+// JSONPath checks the value at a dotted path of the JSON body, list
+// elements are addressed by index, e.g. "users.0.name". want is compared
+// after a JSON round-trip, so 1 equals 1.0 and structs equal objects.
+func (r *Response) JSONPath(path string, want interface{}) *Response {
+	r.t.Helper()
+	got, err := r.lookup(path)
+	if err != nil {
+		r.t.Errorf("geetest: %v", err)
+		return r
+	}
+	b, err := json.Marshal(want)
+	if err != nil {
+		r.t.Errorf("geetest: encoding %v: %v", want, err)
+		return r
+	}
+	var normalized interface{}
+	json.Unmarshal(b, &normalized)
+	if !reflect.DeepEqual(got, normalized) {
+		r.t.Errorf("geetest: JSON %s = %v, want %v", path, got, normalized)
+	}
+	return r
+}
+
+func (r *Response) lookup(path string) (interface{}, error) {
+	if !r.decoded {
+		if err := r.DecodeJSON(&r.json); err != nil {
+			return nil, fmt.Errorf("body is not JSON: %v", err)
+		}
+		r.decoded = true
+	}
+	v := r.json
+	if path == "" {
+		return v, nil
+	}
+	for _, part := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			child, ok := node[part]
+			if !ok {
+				return nil, fmt.Errorf("JSON path %s: no key %q", path, part)
+			}
+			v = child
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("JSON path %s: bad index %q", path, part)
+			}
+			v = node[i]
+		default:
+			return nil, fmt.Errorf("JSON path %s: %q is not an object or list", path, part)
+		}
+	}
+	return v, nil
+}
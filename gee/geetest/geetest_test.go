@@ -0,0 +1,56 @@
+package geetest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"gee"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newEngine() *gee.Engine {
+	gee.SetMode(gee.TestMode)
+	r := gee.New()
+	r.POST("/users", func(c *gee.Context) {
+		var body struct{ Name string }
+		if err := json.NewDecoder(c.Req.Body).Decode(&body); err != nil {
+			c.Fail(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.SetHeader("Location", "/users/1")
+		c.JSON(http.StatusCreated, gee.H{"user": gee.H{"id": 1, "name": body.Name, "tags": []string{c.Query("tag")}}})
+	})
+	return r
+}
+
+func TestClient(t *testing.T) {
+	client := New(t, newEngine())
+	client.POST("/users").Query("tag", "admin").JSON(gee.H{"name": "tom"}).Do().
+		Status(http.StatusCreated).
+		Header("Location", "/users/1").
+		JSONPath("user.id", 1).
+		JSONPath("user.name", "tom").
+		JSONPath("user.tags.0", "admin")
+
+	resp := client.POST("/users").Body("{").Do()
+	if resp.Code() != http.StatusBadRequest {
+		t.Fatalf("invalid JSON should be rejected, got %d", resp.Code())
+	}
+	if _, err := resp.lookup("user.name"); err == nil {
+		t.Fatal("missing keys should be reported")
+	}
+}
+
+func TestCreateTestContext(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("tom:secret")))
+	var user interface{}
+	c, w := CreateTestContext(req, gee.BasicAuth(gee.Accounts{"tom": "secret"}), func(c *gee.Context) {
+		user, _ = c.Get(gee.AuthUserKey)
+	})
+	c.Next()
+	if w.Code != http.StatusOK || user != "tom" {
+		t.Fatalf("middleware should pass to the next handler, got %d %v", w.Code, user)
+	}
+}
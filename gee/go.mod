@@ -1,3 +1,12 @@
 module gee
 
 go 1.21.3
+
+require geecache v0.0.0
+
+require (
+	github.com/golang/protobuf v1.5.4 // indirect
+	google.golang.org/protobuf v1.34.2 // indirect
+)
+
+replace geecache => ../geecache
@@ -0,0 +1,102 @@
+package gee
+
+import (
+	"net"
+	"strings"
+)
+
+// virtualHost holds the routes registered for one host pattern
+type virtualHost struct {
+	pattern string
+	labels  []string
+	router  *router
+}
+
+// Host returns a group whose routes only match requests for host, e.g.
+// "api.example.com", ":tenant.example.com" capturing one label as a param,
+// or "*.example.com" matching any subdomain ("*sub.example.com" captures
+// it). Requests matching no route of their host fall back to the routes
+// registered without a host. The middlewares of a host group only run for
+// the requests its routes serve.
+func (engine *Engine) Host(host string) *RouterGroup {
+	group := &RouterGroup{
+		vhost:  engine.router.host(strings.ToLower(strings.TrimSuffix(host, "."))),
+		parent: engine.RouterGroup,
+		engine: engine,
+	}
+	engine.groups = append(engine.groups, group)
+	return group
+}
+
+// host returns the virtual host of a pattern, creating it on first use.
+// Exact hosts are kept ahead of patterns so they win when both match.
+func (r *router) host(pattern string) *virtualHost {
+	for _, vh := range r.hosts {
+		if vh.pattern == pattern {
+			return vh
+		}
+	}
+	labels := strings.Split(pattern, ".")
+	for i, label := range labels {
+		if label == "" || (label[0] == '*' && i > 0) {
+			panic("gee: invalid host pattern " + pattern)
+		}
+	}
+	vh := &virtualHost{pattern: pattern, labels: labels, router: newRouter()}
+	i := len(r.hosts)
+	if !strings.ContainsAny(pattern, ":*") {
+		for i = 0; i < len(r.hosts) && !strings.ContainsAny(r.hosts[i].pattern, ":*"); i++ {
+		}
+	}
+	r.hosts = append(r.hosts, nil)
+	copy(r.hosts[i+1:], r.hosts[i:])
+	r.hosts[i] = vh
+	return vh
+}
+
+// matchHost reports whether host matches the labels of a pattern and
+// returns the captured labels
+func matchHost(labels []string, host string) (map[string]string, bool) {
+	parts := strings.Split(normalizeHost(host), ".")
+	if len(labels) > 0 && labels[0][0] == '*' {
+		if len(parts) < len(labels) {
+			return nil, false
+		}
+		n := len(parts) - len(labels) + 1
+		params, ok := matchLabels(labels[1:], parts[n:])
+		if ok && len(labels[0]) > 1 {
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[labels[0][1:]] = strings.Join(parts[:n], ".")
+		}
+		return params, ok
+	}
+	if len(parts) != len(labels) {
+		return nil, false
+	}
+	return matchLabels(labels, parts)
+}
+
+func matchLabels(labels, parts []string) (map[string]string, bool) {
+	var params map[string]string
+	for i, label := range labels {
+		if label[0] == ':' {
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[label[1:]] = parts[i]
+		} else if label != parts[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// normalizeHost drops the port and trailing dot and lower-cases host
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
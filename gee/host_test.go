@@ -0,0 +1,94 @@
+package gee
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestHost(t *testing.T) {
+	r := New()
+	r.GET("/", func(c *Context) { c.String(http.StatusOK, "default") })
+	api := r.Host("API.example.com")
+	api.Use(func(c *Context) {
+		c.SetHeader("X-Host", "api")
+		c.Next()
+	})
+	api.GET("/", func(c *Context) { c.String(http.StatusOK, "api") })
+	api.Group("/v1").GET("/users/:id", func(c *Context) { c.String(http.StatusOK, "user %s", c.Param("id")) })
+	r.Host(":tenant.example.com").GET("/", func(c *Context) { c.String(http.StatusOK, "tenant %s", c.Param("tenant")) })
+	r.Host("*sub.static.example.com").GET("/", func(c *Context) { c.String(http.StatusOK, "static %s", c.Param("sub")) })
+
+	tests := []struct {
+		host, path, body, header string
+	}{
+		{"api.example.com", "/", "api", "api"},
+		{"api.example.com:8080", "/v1/users/1", "user 1", "api"},
+		{"acme.example.com", "/", "tenant acme", ""},
+		{"a.b.static.example.com", "/", "static a.b", ""},
+		{"static.example.com", "/", "tenant static", ""},
+		{"other.com", "/", "default", ""},
+		{"api.example.com", "/v1/missing", "404 NOT FOUND: /v1/missing \n", ""},
+		{"other.com", "/v1/users/1", "404 NOT FOUND: /v1/users/1 \n", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		req.Host = tt.host
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Body.String() != tt.body || w.Header().Get("X-Host") != tt.header {
+			t.Fatalf("%s%s = %q %q, want %q %q", tt.host, tt.path, w.Body.String(), w.Header().Get("X-Host"), tt.body, tt.header)
+		}
+	}
+}
+
+func TestHostPatternOrder(t *testing.T) {
+	r := New()
+	r.Host(":tenant.example.com").GET("/", func(c *Context) { c.String(http.StatusOK, "tenant") })
+	r.Host("www.example.com").GET("/", func(c *Context) { c.String(http.StatusOK, "www") })
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Host = "www.example.com"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Body.String() != "www" {
+		t.Fatalf("exact hosts should win over patterns, got %q", w.Body.String())
+	}
+}
+
+func TestHostMiddleware(t *testing.T) {
+	r := New()
+	var ran []string
+	use := func(name string) HandlerFunc {
+		return func(c *Context) {
+			ran = append(ran, name)
+			c.Next()
+		}
+	}
+	api := r.Host("api.example.com")
+	api.Use(use("api"))
+	api.GET("/api", func(c *Context) { c.String(http.StatusOK, "api") })
+	tenant := r.Host(":tenant.example.com")
+	tenant.Use(use("tenant"))
+	tenant.GET("/tenant", func(c *Context) { c.String(http.StatusOK, "tenant") })
+	r.GET("/default", func(c *Context) { c.String(http.StatusOK, "default") })
+
+	tests := []struct {
+		path string
+		ran  []string
+	}{
+		{"/api", []string{"api"}},
+		{"/tenant", []string{"tenant"}},
+		{"/default", nil},
+		{"/missing", nil},
+	}
+	for _, tt := range tests {
+		ran = nil
+		req := httptest.NewRequest("GET", tt.path, nil)
+		req.Host = "api.example.com"
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		if !reflect.DeepEqual(ran, tt.ran) {
+			t.Fatalf("%s ran %v, want %v", tt.path, ran, tt.ran)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package gee
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// JWTClaimsKey is the context key holding the verified Claims
+const JWTClaimsKey = "jwtClaims"
+
+// Claims is the decoded payload of a JWT
+type Claims map[string]interface{}
+
+// Subject returns the "sub" claim
+func (c Claims) Subject() string {
+	s, _ := c["sub"].(string)
+	return s
+}
+
+// JWTConfig selects the accepted algorithms by the keys it holds:
+// Secret enables HS256 and PublicKey enables RS256. An empty non-nil
+// Secret is rejected.
+type JWTConfig struct {
+	Secret    []byte
+	PublicKey *rsa.PublicKey
+	// Leeway tolerates clock skew when checking exp and nbf
+	Leeway time.Duration
+	// Now is used for time based claims, defaults to time.Now
+	Now func() time.Time
+}
+
+var (
+	ErrTokenMalformed   = errors.New("token is malformed")
+	ErrTokenAlgorithm   = errors.New("token algorithm is not accepted")
+	ErrTokenSignature   = errors.New("token signature is invalid")
+	ErrTokenExpired     = errors.New("token is expired")
+	ErrTokenNotValidYet = errors.New("token is not valid yet")
+)
+
+// JWT verifies the bearer token of every request and stores its Claims
+// under JWTClaimsKey and its subject under AuthUserKey
+func JWT(config JWTConfig) HandlerFunc {
+	if config.Secret != nil && len(config.Secret) == 0 {
+		panic("gee: JWT secret can not be empty")
+	}
+	return BearerAuth(func(c *Context, token string) (interface{}, error) {
+		claims, err := ParseJWT(token, config)
+		if err != nil {
+			return nil, err
+		}
+		c.Set(JWTClaimsKey, claims)
+		return claims.Subject(), nil
+	})
+}
+
+// ParseJWT verifies the signature and time based claims of a compact JWT
+func ParseJWT(token string, config JWTConfig) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrTokenMalformed
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrTokenMalformed
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	switch {
+	case header.Alg == "HS256" && len(config.Secret) > 0:
+		mac := hmac.New(sha256.New, config.Secret)
+		mac.Write(signed)
+		if !hmac.Equal(signature, mac.Sum(nil)) {
+			return nil, ErrTokenSignature
+		}
+	case header.Alg == "RS256" && config.PublicKey != nil:
+		sum := sha256.Sum256(signed)
+		if rsa.VerifyPKCS1v15(config.PublicKey, crypto.SHA256, sum[:], signature) != nil {
+			return nil, ErrTokenSignature
+		}
+	default:
+		return nil, ErrTokenAlgorithm
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrTokenMalformed
+	}
+	now := time.Now()
+	if config.Now != nil {
+		now = config.Now()
+	}
+	if exp, ok := claims["exp"].(float64); ok && now.After(time.Unix(int64(exp), 0).Add(config.Leeway)) {
+		return nil, ErrTokenExpired
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Before(time.Unix(int64(nbf), 0).Add(-config.Leeway)) {
+		return nil, ErrTokenNotValidYet
+	}
+	return claims, nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
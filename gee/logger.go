@@ -11,7 +11,14 @@ func Logger() HandlerFunc {
 		t := time.Now()
 		// Process request
 		c.Next()
+		if Mode() == TestMode {
+			return
+		}
 		// Calculate resolution time
+		if id := c.RequestID(); id != "" {
+			log.Printf("[%d] %s in %v (request %s)", c.StatusCode, c.Req.RequestURI, time.Since(t), id)
+			return
+		}
 		log.Printf("[%d] %s in %v", c.StatusCode, c.Req.RequestURI, time.Since(t))
 	}
-}
\ No newline at end of file
+}
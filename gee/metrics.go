@@ -0,0 +1,199 @@
+package gee
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are the latency histogram buckets in seconds
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type metricKey struct {
+	method, route, status string
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+// Metrics records request counts, latency histograms and in-flight requests
+// labeled by method, route pattern and status, and exposes them in the
+// Prometheus text format
+type Metrics struct {
+	namespace string
+	buckets   []float64
+
+	mu       sync.Mutex
+	requests map[metricKey]uint64
+	latency  map[metricKey]*histogram
+	inFlight map[metricKey]int64
+}
+
+// NewMetrics creates metrics named "<namespace>_http_...", buckets default to DefaultBuckets
+func NewMetrics(namespace string, buckets ...float64) *Metrics {
+	if namespace == "" {
+		namespace = "gee"
+	}
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Metrics{
+		namespace: namespace,
+		buckets:   buckets,
+		requests:  make(map[metricKey]uint64),
+		latency:   make(map[metricKey]*histogram),
+		inFlight:  make(map[metricKey]int64),
+	}
+}
+
+// metricMethod bounds the method label, clients may send any method
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return "other"
+}
+
+// Middleware records every request passing through it. Routes are labeled
+// by pattern so "/user/:id" is one series however many users there are.
+func (m *Metrics) Middleware() HandlerFunc {
+	return func(c *Context) {
+		start := time.Now()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		method := metricMethod(c.Method)
+		flight := metricKey{method: method, route: route}
+		m.mu.Lock()
+		m.inFlight[flight]++
+		m.mu.Unlock()
+		panicked := true
+		defer func() {
+			// a panicking request is observed too, as the 500 Recovery answers
+			status := c.StatusCode
+			if panicked {
+				status = http.StatusInternalServerError
+			} else if status == 0 {
+				status = http.StatusOK
+			}
+			m.observe(flight, metricKey{method: method, route: route, status: strconv.Itoa(status)}, time.Since(start).Seconds())
+		}()
+
+		c.Next()
+		panicked = false
+	}
+}
+
+// observe records a finished request and leaves flight
+func (m *Metrics) observe(flight, key metricKey, elapsed float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight[flight]--
+	m.requests[key]++
+	h, ok := m.latency[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.latency[key] = h
+	}
+	if i := sort.SearchFloat64s(m.buckets, elapsed); i < len(m.buckets) {
+		h.counts[i]++
+	}
+	h.sum += elapsed
+	h.count++
+}
+
+// Handler serves the metrics, e.g. r.GET("/metrics", m.Handler())
+func (m *Metrics) Handler() HandlerFunc {
+	return func(c *Context) {
+		m.ServeHTTP(c.Writer, c.Req)
+		c.StatusCode = http.StatusOK
+	}
+}
+
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(m.expose())
+}
+
+func (m *Metrics) expose() []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var buf bytes.Buffer
+	name := m.namespace + "_http_requests_total"
+	fmt.Fprintf(&buf, "# HELP %s Total number of HTTP requests.\n# TYPE %s counter\n", name, name)
+	for _, key := range sortedKeys(m.requests) {
+		fmt.Fprintf(&buf, "%s{%s} %d\n", name, key.labels(), m.requests[key])
+	}
+
+	name = m.namespace + "_http_request_duration_seconds"
+	fmt.Fprintf(&buf, "# HELP %s HTTP request latency in seconds.\n# TYPE %s histogram\n", name, name)
+	for _, key := range sortedKeys(m.latency) {
+		h, labels := m.latency[key], key.labels()
+		var cumulative uint64
+		for i, le := range m.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(&buf, "%s_bucket{%s,le=\"%s\"} %d\n", name, labels, formatFloat(le), cumulative)
+		}
+		fmt.Fprintf(&buf, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+		fmt.Fprintf(&buf, "%s_sum{%s} %s\n", name, labels, formatFloat(h.sum))
+		fmt.Fprintf(&buf, "%s_count{%s} %d\n", name, labels, h.count)
+	}
+
+	name = m.namespace + "_http_requests_in_flight"
+	fmt.Fprintf(&buf, "# HELP %s Number of HTTP requests being served.\n# TYPE %s gauge\n", name, name)
+	for _, key := range sortedKeys(m.inFlight) {
+		fmt.Fprintf(&buf, "%s{%s} %d\n", name, key.labels(), m.inFlight[key])
+	}
+	return buf.Bytes()
+}
+
+func (k metricKey) labels() string {
+	s := fmt.Sprintf(`method="%s",route="%s"`, escapeLabel(k.method), escapeLabel(k.route))
+	if k.status != "" {
+		s += fmt.Sprintf(`,status="%s"`, k.status)
+	}
+	return s
+}
+
+func sortedKeys[V any](m map[metricKey]V) []metricKey {
+	keys := make([]metricKey, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.route != b.route {
+			return a.route < b.route
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.status < b.status
+	})
+	return keys
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,70 @@
+package gee
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics("", 0.5, 1)
+	r := New()
+	r.Use(m.Middleware())
+	r.GET("/users/:id", func(c *Context) { c.String(http.StatusOK, "ok") })
+	r.GET("/metrics", m.Handler())
+
+	for _, path := range []string{"/users/1", "/users/2", "/nope"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+
+	for _, line := range []string{
+		"# TYPE gee_http_requests_total counter",
+		`gee_http_requests_total{method="GET",route="/users/:id",status="200"} 2`,
+		`gee_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`gee_http_request_duration_seconds_bucket{method="GET",route="/users/:id",status="200",le="0.5"} 2`,
+		`gee_http_request_duration_seconds_bucket{method="GET",route="/users/:id",status="200",le="+Inf"} 2`,
+		`gee_http_request_duration_seconds_count{method="GET",route="/users/:id",status="200"} 2`,
+		`gee_http_requests_in_flight{method="GET",route="/metrics"} 1`,
+		`gee_http_requests_in_flight{method="GET",route="/users/:id"} 0`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Fatalf("missing %q in\n%s", line, body)
+		}
+	}
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Fatalf("unexpected content type %q", w.Header().Get("Content-Type"))
+	}
+}
+
+func TestEscapeLabel(t *testing.T) {
+	if got := escapeLabel("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Fatalf("escapeLabel = %s", got)
+	}
+}
+
+func TestMetricsPanicAndMethods(t *testing.T) {
+	m := NewMetrics("")
+	r := New()
+	r.Use(Recovery(), m.Middleware())
+	r.GET("/p", func(c *Context) { panic("boom") })
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/p", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("BREW", "/x", nil))
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+	if !strings.Contains(body, `gee_http_requests_in_flight{method="GET",route="/p"} 0`) {
+		t.Fatalf("panicking requests should leave flight:\n%s", body)
+	}
+	if !strings.Contains(body, `gee_http_requests_total{method="GET",route="/p",status="500"} 1`) ||
+		!strings.Contains(body, `gee_http_request_duration_seconds_count{method="GET",route="/p",status="500"} 1`) {
+		t.Fatalf("panicking requests should be counted as 500:\n%s", body)
+	}
+	if strings.Contains(body, "BREW") || !strings.Contains(body, `method="other"`) {
+		t.Fatalf("unknown methods should be collapsed:\n%s", body)
+	}
+}
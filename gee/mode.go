@@ -0,0 +1,60 @@
+package gee
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync/atomic"
+)
+
+// EnvGeeMode is the environment variable selecting the initial mode
+const EnvGeeMode = "GEE_MODE"
+
+const (
+	// DebugMode prints the routes and warnings, it is the default
+	DebugMode = "debug"
+	// ReleaseMode prints nothing but the request logs
+	ReleaseMode = "release"
+	// TestMode silences the request logs and panic traces too
+	TestMode = "test"
+)
+
+var geeMode atomic.Value
+
+func init() {
+	SetMode(os.Getenv(EnvGeeMode))
+}
+
+// SetMode sets the mode of every engine, "" selects DebugMode
+func SetMode(value string) {
+	switch value {
+	case "":
+		value = DebugMode
+	case DebugMode, ReleaseMode, TestMode:
+	default:
+		panic("gee: unknown mode " + value + ", use debug, release or test")
+	}
+	geeMode.Store(value)
+}
+
+// Mode returns the current mode
+func Mode() string {
+	return geeMode.Load().(string)
+}
+
+// IsDebugging reports whether the engine runs in DebugMode
+func IsDebugging() bool {
+	return Mode() == DebugMode
+}
+
+func debugPrint(format string, values ...interface{}) {
+	if IsDebugging() {
+		log.Printf("[GEE-debug] "+format, values...)
+	}
+}
+
+func debugPrintWarning(format string, values ...interface{}) {
+	if IsDebugging() {
+		log.Printf("[GEE-debug] [WARNING] %s", fmt.Sprintf(format, values...))
+	}
+}
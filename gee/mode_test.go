@@ -0,0 +1,58 @@
+package gee
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	SetMode(TestMode)
+	os.Exit(m.Run())
+}
+
+func TestSetMode(t *testing.T) {
+	defer SetMode(TestMode)
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	SetMode(DebugMode)
+	r := Default()
+	r.GET("/", func(c *Context) { c.String(http.StatusOK, "ok") })
+	if out := buf.String(); !strings.Contains(out, "[WARNING]") || !strings.Contains(out, "Route GET     /") {
+		t.Fatalf("debug mode should print warnings and routes, got %q", out)
+	}
+
+	buf.Reset()
+	SetMode(ReleaseMode)
+	r = Default()
+	r.GET("/", func(c *Context) { c.String(http.StatusOK, "ok") })
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if out := buf.String(); strings.Contains(out, "GEE-debug") || !strings.Contains(out, "[200] /") {
+		t.Fatalf("release mode should only log requests, got %q", out)
+	}
+
+	buf.Reset()
+	SetMode(TestMode)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if buf.Len() != 0 {
+		t.Fatalf("test mode should be silent, got %q", buf.String())
+	}
+
+	SetMode("")
+	if !IsDebugging() {
+		t.Fatal("empty mode should select debug")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("unknown mode should panic")
+		}
+	}()
+	SetMode("prod")
+}
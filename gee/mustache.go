@@ -0,0 +1,274 @@
+package gee
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// MustacheRender is a minimal logic-less template engine supporting a
+// subset of mustache:
+//
+//	{{name}} {{a.b}} {{.}}   escaped values
+//	{{{name}}} {{&name}}     raw values
+//	{{#list}}..{{/list}}     sections, repeated for lists
+//	{{^list}}..{{/list}}     inverted sections
+//	{{>partial}}             other templates of the render
+//	{{! comment}}
+type MustacheRender struct {
+	mu        sync.RWMutex
+	templates map[string][]mustacheNode
+}
+
+func NewMustacheRender() *MustacheRender {
+	return &MustacheRender{templates: make(map[string][]mustacheNode)}
+}
+
+// Add parses src as the template called name
+func (r *MustacheRender) Add(name, src string) error {
+	nodes, rest, err := parseMustache(src, "")
+	if err != nil {
+		return fmt.Errorf("gee: mustache %s: %v", name, err)
+	}
+	if rest != "" {
+		return fmt.Errorf("gee: mustache %s: unexpected closing tag", name)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.templates[name] = nodes
+	return nil
+}
+
+// AddFS adds the files of fsys matching pattern, named after their base name
+func (r *MustacheRender) AddFS(fsys fs.FS, pattern string) error {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		b, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		if err := r.Add(path.Base(file), string(b)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *MustacheRender) Render(w io.Writer, name string, data interface{}) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	nodes, ok := r.templates[name]
+	if !ok {
+		return fmt.Errorf("gee: mustache template %q is not defined", name)
+	}
+	var b strings.Builder
+	if err := r.execute(&b, nodes, []interface{}{data}, 0); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type mustacheKind int
+
+const (
+	mustacheText mustacheKind = iota
+	mustacheVar
+	mustacheRaw
+	mustacheSection
+	mustacheInverted
+	mustachePartial
+)
+
+type mustacheNode struct {
+	kind     mustacheKind
+	value    string // text, or the name of a tag
+	children []mustacheNode
+}
+
+// parseMustache parses until the closing tag of section, returning the
+// source left after it
+func parseMustache(src, section string) ([]mustacheNode, string, error) {
+	var nodes []mustacheNode
+	for {
+		start := strings.Index(src, "{{")
+		if start < 0 {
+			if section != "" {
+				return nil, "", fmt.Errorf("section %q is not closed", section)
+			}
+			if src != "" {
+				nodes = append(nodes, mustacheNode{kind: mustacheText, value: src})
+			}
+			return nodes, "", nil
+		}
+		if start > 0 {
+			nodes = append(nodes, mustacheNode{kind: mustacheText, value: src[:start]})
+		}
+		src = src[start:]
+
+		closing := "}}"
+		if strings.HasPrefix(src, "{{{") {
+			closing = "}}}"
+		}
+		end := strings.Index(src, closing)
+		if end < 0 {
+			return nil, "", fmt.Errorf("unclosed tag")
+		}
+		tag := src[2:end]
+		src = src[end+len(closing):]
+		if closing == "}}}" {
+			nodes = append(nodes, mustacheNode{kind: mustacheRaw, value: strings.TrimSpace(tag[1:])})
+			continue
+		}
+
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			return nil, "", fmt.Errorf("empty tag")
+		}
+		name := strings.TrimSpace(tag[1:])
+		switch tag[0] {
+		case '!':
+		case '&':
+			nodes = append(nodes, mustacheNode{kind: mustacheRaw, value: name})
+		case '>':
+			nodes = append(nodes, mustacheNode{kind: mustachePartial, value: name})
+		case '#', '^':
+			children, rest, err := parseMustache(src, name)
+			if err != nil {
+				return nil, "", err
+			}
+			kind := mustacheSection
+			if tag[0] == '^' {
+				kind = mustacheInverted
+			}
+			nodes = append(nodes, mustacheNode{kind: kind, value: name, children: children})
+			src = rest
+		case '/':
+			if name != section {
+				return nil, "", fmt.Errorf("unexpected closing tag %q", name)
+			}
+			return nodes, src, nil
+		default:
+			nodes = append(nodes, mustacheNode{kind: mustacheVar, value: tag})
+		}
+	}
+}
+
+func (r *MustacheRender) execute(b *strings.Builder, nodes []mustacheNode, stack []interface{}, depth int) error {
+	if depth > 32 {
+		return fmt.Errorf("gee: mustache partials nested too deeply")
+	}
+	for _, node := range nodes {
+		switch node.kind {
+		case mustacheText:
+			b.WriteString(node.value)
+		case mustacheVar, mustacheRaw:
+			v, ok := lookupMustache(stack, node.value)
+			if !ok || v == nil {
+				continue
+			}
+			s := fmt.Sprint(v)
+			if node.kind == mustacheVar {
+				s = template.HTMLEscapeString(s)
+			}
+			b.WriteString(s)
+		case mustachePartial:
+			partial, ok := r.templates[node.value]
+			if !ok {
+				return fmt.Errorf("gee: mustache partial %q is not defined", node.value)
+			}
+			if err := r.execute(b, partial, stack, depth+1); err != nil {
+				return err
+			}
+		case mustacheSection, mustacheInverted:
+			v, _ := lookupMustache(stack, node.value)
+			rv := reflect.ValueOf(v)
+			truthy := v != nil && !rv.IsZero()
+			isList := v != nil && (rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array)
+			if isList {
+				truthy = rv.Len() > 0
+			}
+			if node.kind == mustacheInverted {
+				if !truthy {
+					if err := r.execute(b, node.children, stack, depth); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if !truthy {
+				continue
+			}
+			if !isList {
+				if err := r.execute(b, node.children, append(stack, v), depth); err != nil {
+					return err
+				}
+				continue
+			}
+			for i := 0; i < rv.Len(); i++ {
+				if err := r.execute(b, node.children, append(stack, rv.Index(i).Interface()), depth); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// lookupMustache resolves a dotted name against the innermost context
+// defining its first part
+func lookupMustache(stack []interface{}, name string) (interface{}, bool) {
+	if name == "." {
+		return stack[len(stack)-1], true
+	}
+	parts := strings.Split(name, ".")
+	for i := len(stack) - 1; i >= 0; i-- {
+		v, ok := mustacheField(stack[i], parts[0])
+		if !ok {
+			continue
+		}
+		for _, part := range parts[1:] {
+			if v, ok = mustacheField(v, part); !ok {
+				return nil, false
+			}
+		}
+		return v, true
+	}
+	return nil, false
+}
+
+func mustacheField(data interface{}, name string) (interface{}, bool) {
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		f := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+		if !f.IsValid() {
+			return nil, false
+		}
+		return f.Interface(), true
+	case reflect.Struct:
+		f := v.FieldByName(name)
+		if !f.IsValid() || !f.CanInterface() {
+			return nil, false
+		}
+		return f.Interface(), true
+	}
+	return nil, false
+}
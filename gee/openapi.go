@@ -0,0 +1,302 @@
+package gee
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RouteDoc is the OpenAPI metadata of a route
+type RouteDoc struct {
+	Summary     string
+	Description string
+	Tags        []string
+	Deprecated  bool
+	// Request is a value of the JSON request body type, e.g. User{}
+	Request interface{}
+	// Response is a value of the JSON response body type
+	Response interface{}
+	// Status is the status of a successful response, 200 by default
+	Status int
+}
+
+// Doc attaches OpenAPI metadata to the route registered with the same
+// method and pattern on the group
+func (group *RouterGroup) Doc(method, pattern string, doc RouteDoc) *RouterGroup {
+	engine := group.engine
+	if engine.docs == nil {
+		engine.docs = make(map[string]RouteDoc)
+	}
+	host := ""
+	if group.vhost != nil {
+		host = group.vhost.pattern
+	}
+	engine.docs[method+" "+host+group.prefix+pattern] = doc
+	return group
+}
+
+// OpenAPIConfig configures the generated OpenAPI document
+type OpenAPIConfig struct {
+	Title       string
+	Version     string
+	Description string
+	// Path serves the document as JSON, "/openapi.json" by default
+	Path string
+	// UIPath serves a page browsing the document, "/docs" by default, "-" disables it
+	UIPath string
+}
+
+// OpenAPI serves an OpenAPI 3 document of the routes registered without a
+// host, generated on each request so later routes are included
+func (engine *Engine) OpenAPI(config OpenAPIConfig) {
+	if config.Path == "" {
+		config.Path = "/openapi.json"
+	}
+	if config.UIPath == "" {
+		config.UIPath = "/docs"
+	}
+	engine.addRoute(http.MethodGet, config.Path, func(c *Context) {
+		c.JSON(http.StatusOK, engine.OpenAPISpec(config))
+	})
+	if config.UIPath != "-" {
+		engine.addRoute(http.MethodGet, config.UIPath, func(c *Context) {
+			c.SetHeader("Content-Type", "text/html; charset=utf-8")
+			c.Status(http.StatusOK)
+			c.Writer.Write([]byte(strings.Replace(openAPIPage, "{{spec}}", strconv.Quote(config.Path), 1)))
+		})
+	}
+}
+
+// OpenAPISpec generates the OpenAPI 3 document of the routes registered
+// without a host, leaving out the routes serving it
+func (engine *Engine) OpenAPISpec(config OpenAPIConfig) H {
+	schemas := H{}
+	paths := H{}
+	for _, route := range engine.routes {
+		if route.Host != "" || route.Path == config.Path || route.Path == config.UIPath || !openAPIMethods[route.Method] {
+			continue
+		}
+		openPath, params := openAPIPath(route.Path)
+		item, ok := paths[openPath].(H)
+		if !ok {
+			item = H{}
+			paths[openPath] = item
+		}
+		item[strings.ToLower(route.Method)] = openAPIOperation(route, params, engine.docs[route.Method+" "+route.Path], schemas)
+	}
+	info := H{"title": config.Title, "version": config.Version}
+	if info["title"] == "" {
+		info["title"] = "gee"
+	}
+	if info["version"] == "" {
+		info["version"] = "0.0.0"
+	}
+	if config.Description != "" {
+		info["description"] = config.Description
+	}
+	spec := H{"openapi": "3.0.3", "info": info, "paths": paths}
+	if len(schemas) > 0 {
+		spec["components"] = H{"schemas": schemas}
+	}
+	return spec
+}
+
+// openAPIMethods are the operations of an OpenAPI 3.0 Path Item, CONNECT
+// routes registered by Any and Mount are left out
+var openAPIMethods = map[string]bool{
+	http.MethodGet: true, http.MethodPut: true, http.MethodPost: true, http.MethodDelete: true,
+	http.MethodOptions: true, http.MethodHead: true, http.MethodPatch: true, http.MethodTrace: true,
+}
+
+// openAPIPath turns /users/:id and /static/*filepath into templated paths
+// and returns their parameter names
+func openAPIPath(pattern string) (string, []string) {
+	var params []string
+	parts := strings.Split(pattern, "/")
+	for i, part := range parts {
+		if part == "" || (part[0] != ':' && part[0] != '*') {
+			continue
+		}
+		name := part[1:]
+		if name == "" {
+			name = "wildcard"
+		}
+		params = append(params, name)
+		parts[i] = "{" + name + "}"
+	}
+	return strings.Join(parts, "/"), params
+}
+
+func openAPIOperation(route RouteInfo, params []string, doc RouteDoc, schemas H) H {
+	op := H{}
+	if doc.Summary != "" {
+		op["summary"] = doc.Summary
+	}
+	if doc.Description != "" {
+		op["description"] = doc.Description
+	}
+	if len(doc.Tags) > 0 {
+		op["tags"] = doc.Tags
+	}
+	if doc.Deprecated {
+		op["deprecated"] = true
+	}
+	if len(params) > 0 {
+		parameters := make([]H, 0, len(params))
+		for _, name := range params {
+			parameters = append(parameters, H{"name": name, "in": "path", "required": true, "schema": H{"type": "string"}})
+		}
+		op["parameters"] = parameters
+	}
+	if doc.Request != nil {
+		op["requestBody"] = H{
+			"required": true,
+			"content":  H{"application/json": H{"schema": reflectSchema(reflect.TypeOf(doc.Request), schemas)}},
+		}
+	}
+	status := doc.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	response := H{"description": http.StatusText(status)}
+	if doc.Response != nil {
+		response["content"] = H{"application/json": H{"schema": reflectSchema(reflect.TypeOf(doc.Response), schemas)}}
+	}
+	op["responses"] = H{strconv.Itoa(status): response}
+	return op
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// reflectSchema returns the JSON schema of t, named structs are added to
+// schemas and referenced
+func reflectSchema(t reflect.Type, schemas H) H {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return H{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Bool:
+		return H{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		format := "int64"
+		if t.Bits() <= 32 {
+			format = "int32"
+		}
+		return H{"type": "integer", "format": format}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return H{"type": "number"}
+	case t.Kind() == reflect.String:
+		return H{"type": "string"}
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return H{"type": "string", "format": "byte"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		return H{"type": "array", "items": reflectSchema(t.Elem(), schemas)}
+	case t.Kind() == reflect.Map:
+		return H{"type": "object", "additionalProperties": reflectSchema(t.Elem(), schemas)}
+	case t.Kind() == reflect.Struct:
+		if t.Name() == "" {
+			return structSchema(t, schemas)
+		}
+		ref := H{"$ref": "#/components/schemas/" + t.Name()}
+		if _, ok := schemas[t.Name()]; !ok {
+			schemas[t.Name()] = H{} // placeholder for recursive types
+			schemas[t.Name()] = structSchema(t, schemas)
+		}
+		return ref
+	}
+	return H{}
+}
+
+// structSchema describes the fields encoding/json would encode, fields
+// without omitempty are required
+func structSchema(t reflect.Type, schemas H) H {
+	properties := H{}
+	var required []string
+	var addFields func(t reflect.Type)
+	addFields = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			tag := field.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if field.Anonymous && name == "" {
+				ft := field.Type
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if ft.Kind() == reflect.Struct {
+					addFields(ft)
+					continue
+				}
+			}
+			if !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			properties[name] = reflectSchema(field.Type, schemas)
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+	}
+	addFields(t)
+	schema := H{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// openAPIPage lists the operations of the document at {{spec}}
+const openAPIPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>API</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 60em; }
+details { border: 1px solid #ddd; border-radius: 4px; margin: .5em 0; padding: .5em; }
+summary { cursor: pointer; }
+.method { display: inline-block; width: 5em; font-weight: bold; text-transform: uppercase; }
+.deprecated { text-decoration: line-through; }
+pre { background: #f6f6f6; padding: .5em; overflow: auto; }
+</style>
+</head>
+<body>
+<h1 id="title">API</h1>
+<div id="operations"></div>
+<script>
+fetch({{spec}}).then(function (r) { return r.json(); }).then(function (spec) {
+  document.getElementById("title").textContent = spec.info.title + " " + spec.info.version;
+  var list = document.getElementById("operations");
+  Object.keys(spec.paths).sort().forEach(function (path) {
+    Object.keys(spec.paths[path]).forEach(function (method) {
+      var op = spec.paths[path][method];
+      var details = document.createElement("details");
+      var summary = document.createElement("summary");
+      var name = document.createElement("span");
+      name.className = "method";
+      name.textContent = method;
+      summary.appendChild(name);
+      summary.appendChild(document.createTextNode(path + (op.summary ? " - " + op.summary : "")));
+      if (op.deprecated) summary.className = "deprecated";
+      var body = document.createElement("pre");
+      body.textContent = JSON.stringify(op, null, 2);
+      details.appendChild(summary);
+      details.appendChild(body);
+      list.appendChild(details);
+    });
+  });
+});
+</script>
+</body>
+</html>
+`
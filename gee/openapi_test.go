@@ -0,0 +1,80 @@
+package gee
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type openAPIUser struct {
+	ID      int64             `json:"id"`
+	Name    string            `json:"name"`
+	Email   string            `json:"email,omitempty"`
+	Friends []*openAPIUser    `json:"friends,omitempty"`
+	Created time.Time         `json:"created"`
+	Labels  map[string]string `json:"-"`
+}
+
+func TestOpenAPI(t *testing.T) {
+	r := New()
+	ok := func(c *Context) {}
+	v1 := r.Group("/v1")
+	v1.GET("/users/:id", ok)
+	v1.Doc("GET", "/users/:id", RouteDoc{Summary: "Get a user", Tags: []string{"users"}, Response: openAPIUser{}})
+	v1.POST("/users", ok)
+	v1.Doc("POST", "/users", RouteDoc{Request: &openAPIUser{}, Response: openAPIUser{}, Status: http.StatusCreated})
+	r.GET("/files/*filepath", ok)
+	r.Host("admin.example.com").GET("/secret", ok)
+	r.Any("/any", ok)
+	r.OpenAPI(OpenAPIConfig{Title: "users", Version: "1.0"})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/openapi.json", nil))
+	var spec map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &spec); err != nil {
+		t.Fatal(err)
+	}
+	get := func(path string) interface{} {
+		var v interface{} = spec
+		for _, key := range strings.Split(path, ".") {
+			v = v.(map[string]interface{})[key]
+		}
+		return v
+	}
+
+	paths := get("paths").(map[string]interface{})
+	var keys []string
+	for key := range paths {
+		keys = append(keys, key)
+	}
+	if len(keys) != 4 || get("paths./any.connect") != nil || get("paths./any.trace") == nil || paths["/v1/users/{id}"] == nil || paths["/files/{filepath}"] == nil || paths["/v1/users"] == nil {
+		t.Fatalf("paths = %v", keys)
+	}
+	if get("paths./v1/users/{id}.get.summary") != "Get a user" ||
+		get("paths./v1/users/{id}.get.responses.200.content.application/json.schema.$ref") != "#/components/schemas/openAPIUser" ||
+		get("paths./v1/users.post.requestBody.content.application/json.schema.$ref") != "#/components/schemas/openAPIUser" ||
+		get("paths./v1/users.post.responses.201.description") != "Created" {
+		t.Fatalf("unexpected operations %v", paths)
+	}
+	params := get("paths./files/{filepath}.get.parameters").([]interface{})
+	if len(params) != 1 || params[0].(map[string]interface{})["name"] != "filepath" {
+		t.Fatalf("parameters = %v", params)
+	}
+	user := get("components.schemas.openAPIUser").(map[string]interface{})
+	if !reflect.DeepEqual(user["required"], []interface{}{"id", "name", "created"}) ||
+		get("components.schemas.openAPIUser.properties.friends.items.$ref") != "#/components/schemas/openAPIUser" ||
+		get("components.schemas.openAPIUser.properties.created.format") != "date-time" ||
+		get("components.schemas.openAPIUser.properties.id.format") != "int64" {
+		t.Fatalf("schema = %v", user)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/docs", nil))
+	if !strings.Contains(w.Body.String(), `fetch("/openapi.json")`) {
+		t.Fatalf("the UI should load the document, got %s", w.Body.String())
+	}
+}
@@ -0,0 +1,320 @@
+package gee
+
+import (
+	"context"
+	"errors"
+	"geecache/consistenthash"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ProxyTarget is an upstream server of a Proxy
+type ProxyTarget struct {
+	URL    *url.URL
+	active int64 // requests in flight
+
+	mu        sync.Mutex
+	fails     int
+	downUntil time.Time
+}
+
+// Active returns the number of requests in flight to the target
+func (t *ProxyTarget) Active() int64 {
+	return atomic.LoadInt64(&t.active)
+}
+
+// Healthy reports whether the target is not taken out by failures
+func (t *ProxyTarget) Healthy() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return !time.Now().Before(t.downUntil)
+}
+
+// Balancer picks the target of a request among the healthy candidates
+type Balancer interface {
+	Next(c *Context, targets []*ProxyTarget) *ProxyTarget
+}
+
+type roundRobin struct {
+	next uint64
+}
+
+// RoundRobin returns a Balancer cycling through the targets
+func RoundRobin() Balancer {
+	return &roundRobin{}
+}
+
+func (b *roundRobin) Next(c *Context, targets []*ProxyTarget) *ProxyTarget {
+	n := atomic.AddUint64(&b.next, 1) - 1
+	return targets[n%uint64(len(targets))]
+}
+
+type leastConnections struct{}
+
+// LeastConnections returns a Balancer picking the target with the fewest
+// requests in flight, the first one on ties
+func LeastConnections() Balancer {
+	return leastConnections{}
+}
+
+func (leastConnections) Next(c *Context, targets []*ProxyTarget) *ProxyTarget {
+	best := targets[0]
+	for _, t := range targets[1:] {
+		if t.Active() < best.Active() {
+			best = t
+		}
+	}
+	return best
+}
+
+type consistentHash struct {
+	key      RateKeyFunc
+	replicas int
+
+	mu    sync.Mutex
+	rings map[string]*consistenthash.Map // by candidate set
+}
+
+// ConsistentHash returns a Balancer sending the requests of a key, e.g.
+// KeyByIP(), to the same target while the healthy targets are unchanged
+func ConsistentHash(key RateKeyFunc) Balancer {
+	return &consistentHash{key: key, replicas: 50, rings: make(map[string]*consistenthash.Map)}
+}
+
+func (b *consistentHash) Next(c *Context, targets []*ProxyTarget) *ProxyTarget {
+	names := make([]string, len(targets))
+	byName := make(map[string]*ProxyTarget, len(targets))
+	for i, t := range targets {
+		names[i] = t.URL.String()
+		byName[names[i]] = t
+	}
+	set := strings.Join(names, " ")
+	b.mu.Lock()
+	ring, ok := b.rings[set]
+	if !ok {
+		ring = consistenthash.New(b.replicas, nil)
+		ring.Add(names...)
+		b.rings[set] = ring
+	}
+	b.mu.Unlock()
+	return byName[ring.Get(b.key(c))]
+}
+
+// ProxyConfig configures a reverse proxy
+type ProxyConfig struct {
+	// Targets are the upstream base URLs, e.g. "http://10.0.0.1:8080"
+	Targets []string
+	// Balancer picks the target of each request, RoundRobin by default
+	Balancer Balancer
+	// MaxFails consecutive failures take a target out for FailTimeout,
+	// 3 and 10 seconds by default. Failures are transport errors and 502,
+	// 503 and 504 responses.
+	MaxFails    int
+	FailTimeout time.Duration
+	// Retries is how many other targets are tried after a transport error,
+	// for idempotent requests without body only, 2 by default, -1 disables
+	Retries int
+	// StripPrefix is removed from the path before forwarding
+	StripPrefix string
+	// PreserveHost forwards the Host header of the client instead of the
+	// host of the target
+	PreserveHost bool
+	// Rewrite is called last to edit the outgoing request, e.g. its headers
+	Rewrite func(*httputil.ProxyRequest)
+	// ModifyResponse edits the upstream response before it is copied. An
+	// error answers 502 without retrying or counting a target failure.
+	ModifyResponse func(*http.Response) error
+	// Transport sends the upstream requests, http.DefaultTransport by default
+	Transport http.RoundTripper
+}
+
+// Proxy forwards requests to targets, balanced round-robin
+func Proxy(targets ...string) HandlerFunc {
+	return ProxyWithConfig(ProxyConfig{Targets: targets})
+}
+
+type proxyAttemptKey struct{}
+
+// proxyAttempt carries the state of one upstream attempt through the
+// ReverseProxy callbacks
+type proxyAttempt struct {
+	c      *Context
+	target *ProxyTarget
+	err    error
+	// modified is set when ModifyResponse rejected a response, which is
+	// not a failure of the target
+	modified bool
+}
+
+// ProxyWithConfig returns a reverse proxy handler. X-Forwarded-For,
+// -Host and -Proto are set from the client, keeping the ones sent by
+// trusted proxies.
+func ProxyWithConfig(config ProxyConfig) HandlerFunc {
+	if len(config.Targets) == 0 {
+		panic("gee: proxy needs at least one target")
+	}
+	targets := make([]*ProxyTarget, len(config.Targets))
+	for i, raw := range config.Targets {
+		u, err := url.Parse(raw)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			panic("gee: invalid proxy target " + raw)
+		}
+		targets[i] = &ProxyTarget{URL: u}
+	}
+	if config.Balancer == nil {
+		config.Balancer = RoundRobin()
+	}
+	if config.MaxFails <= 0 {
+		config.MaxFails = 3
+	}
+	if config.FailTimeout <= 0 {
+		config.FailTimeout = 10 * time.Second
+	}
+	if config.Retries == 0 {
+		config.Retries = 2
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Transport: config.Transport,
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			attempt := pr.In.Context().Value(proxyAttemptKey{}).(*proxyAttempt)
+			c := attempt.c
+			pr.SetURL(attempt.target.URL)
+			if config.StripPrefix != "" {
+				pr.Out.URL.Path = singleJoiningSlash(attempt.target.URL.Path, strings.TrimPrefix(pr.In.URL.Path, config.StripPrefix))
+				pr.Out.URL.RawPath = ""
+			}
+			if config.PreserveHost {
+				pr.Out.Host = pr.In.Host
+			}
+			if c.fromTrustedProxy() {
+				if prior := pr.In.Header.Values("X-Forwarded-For"); len(prior) > 0 {
+					pr.Out.Header["X-Forwarded-For"] = prior
+				}
+			}
+			pr.SetXForwarded()
+			pr.Out.Header.Set("X-Forwarded-Host", c.Host())
+			pr.Out.Header.Set("X-Forwarded-Proto", c.Scheme())
+			if config.Rewrite != nil {
+				config.Rewrite(pr)
+			}
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			attempt := resp.Request.Context().Value(proxyAttemptKey{}).(*proxyAttempt)
+			switch resp.StatusCode {
+			case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+				attempt.target.fail(config.MaxFails, config.FailTimeout)
+			default:
+				attempt.target.succeed()
+			}
+			if config.ModifyResponse != nil {
+				err := config.ModifyResponse(resp)
+				attempt.modified = err != nil
+				return err
+			}
+			return nil
+		},
+		ErrorHandler: func(w http.ResponseWriter, req *http.Request, err error) {
+			req.Context().Value(proxyAttemptKey{}).(*proxyAttempt).err = err
+		},
+	}
+
+	return func(c *Context) {
+		retryable := isIdempotent(c.Method) && c.Req.ContentLength == 0
+		tried := make(map[*ProxyTarget]bool)
+		var err error
+		for try := 0; try == 0 || (retryable && try <= config.Retries && len(tried) < len(targets)); try++ {
+			target := config.Balancer.Next(c, proxyCandidates(targets, tried))
+			tried[target] = true
+			attempt := &proxyAttempt{c: c, target: target}
+			func() {
+				// ReverseProxy panics with http.ErrAbortHandler when copying the body fails
+				atomic.AddInt64(&target.active, 1)
+				defer atomic.AddInt64(&target.active, -1)
+				req := c.Req.WithContext(context.WithValue(c.Req.Context(), proxyAttemptKey{}, attempt))
+				proxy.ServeHTTP(&statusWriter{ResponseWriter: c.Writer, c: c}, req)
+			}()
+			if attempt.err == nil {
+				return
+			}
+			err = attempt.err
+			if errors.Is(err, context.Canceled) && c.Req.Context().Err() != nil {
+				return
+			}
+			if attempt.modified {
+				log.Printf("gee: proxy %s %s modify response from %s: %v", c.Method, c.Path, target.URL, err)
+				break
+			}
+			target.fail(config.MaxFails, config.FailTimeout)
+			log.Printf("gee: proxy %s %s to %s: %v", c.Method, c.Path, target.URL, err)
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			c.Fail(http.StatusGatewayTimeout, "Gateway Timeout")
+			return
+		}
+		c.Fail(http.StatusBadGateway, "Bad Gateway")
+	}
+}
+
+// proxyCandidates returns the healthy targets not tried yet, or all the
+// targets not tried when none is healthy
+func proxyCandidates(targets []*ProxyTarget, tried map[*ProxyTarget]bool) []*ProxyTarget {
+	var healthy, rest []*ProxyTarget
+	for _, t := range targets {
+		if tried[t] {
+			continue
+		}
+		if t.Healthy() {
+			healthy = append(healthy, t)
+		} else {
+			rest = append(rest, t)
+		}
+	}
+	if len(healthy) > 0 {
+		return healthy
+	}
+	if len(rest) > 0 {
+		return rest
+	}
+	return targets
+}
+
+func (t *ProxyTarget) fail(maxFails int, timeout time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.fails++
+	if t.fails >= maxFails {
+		t.fails = 0
+		t.downUntil = time.Now().Add(timeout)
+	}
+}
+
+func (t *ProxyTarget) succeed() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.fails = 0
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func singleJoiningSlash(a, b string) string {
+	switch {
+	case strings.HasSuffix(a, "/") && strings.HasPrefix(b, "/"):
+		return a + b[1:]
+	case !strings.HasSuffix(a, "/") && !strings.HasPrefix(b, "/"):
+		return a + "/" + b
+	}
+	return a + b
+}
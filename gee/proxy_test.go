@@ -0,0 +1,150 @@
+package gee
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"testing"
+)
+
+func newUpstream(name string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(w, "%s %s %s %s", name, req.URL.Path, req.Header.Get("X-Forwarded-Host"), req.Header.Get("X-Test"))
+	}))
+}
+
+func proxyGet(r *Engine, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", path, nil)
+	req.Host = "front.example.com"
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestProxyRoundRobin(t *testing.T) {
+	a, b := newUpstream("a"), newUpstream("b")
+	defer a.Close()
+	defer b.Close()
+	r := New()
+	r.Any("/api/*path", ProxyWithConfig(ProxyConfig{
+		Targets:     []string{a.URL, b.URL},
+		StripPrefix: "/api",
+		Rewrite:     func(pr *httputil.ProxyRequest) { pr.Out.Header.Set("X-Test", "1") },
+	}))
+
+	for _, want := range []string{"a /users front.example.com 1", "b /users front.example.com 1", "a /users front.example.com 1"} {
+		if w := proxyGet(r, "/api/users"); w.Body.String() != want {
+			t.Fatalf("got %d %q, want %q", w.Code, w.Body.String(), want)
+		}
+	}
+}
+
+func TestProxyRetryAndHealth(t *testing.T) {
+	dead := newUpstream("dead")
+	dead.Close()
+	live := newUpstream("live")
+	defer live.Close()
+	r := New()
+	r.GET("/*path", ProxyWithConfig(ProxyConfig{Targets: []string{dead.URL, live.URL}, MaxFails: 1}))
+	r.POST("/*path", ProxyWithConfig(ProxyConfig{Targets: []string{dead.URL}}))
+
+	for i := 0; i < 3; i++ {
+		if w := proxyGet(r, "/x"); w.Code != http.StatusOK || w.Body.String() != "live /x front.example.com " {
+			t.Fatalf("idempotent requests should be retried on the live target, got %d %q", w.Code, w.Body.String())
+		}
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/x", nil))
+	if w.Code != http.StatusBadGateway {
+		t.Fatalf("unreachable targets should give 502, got %d", w.Code)
+	}
+}
+
+func TestProxyBalancers(t *testing.T) {
+	busy := &ProxyTarget{active: 3}
+	idle := &ProxyTarget{active: 1}
+	if LeastConnections().Next(nil, []*ProxyTarget{busy, idle}) != idle {
+		t.Fatal("least connections should pick the idle target")
+	}
+
+	a, b := newUpstream("a"), newUpstream("b")
+	defer a.Close()
+	defer b.Close()
+	r := New()
+	r.GET("/*path", ProxyWithConfig(ProxyConfig{Targets: []string{a.URL, b.URL}, Balancer: ConsistentHash(KeyByHeader("X-User"))}))
+	seen := make(map[string]string)
+	for i := 0; i < 20; i++ {
+		user := fmt.Sprint("user", i%5)
+		req := httptest.NewRequest("GET", "/x", nil)
+		req.Header.Set("X-User", user)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("got %d", w.Code)
+		}
+		if got, ok := seen[user]; ok && got != w.Body.String()[:1] {
+			t.Fatalf("%s moved from %s to %s", user, got, w.Body.String()[:1])
+		}
+		seen[user] = w.Body.String()[:1]
+	}
+}
+
+type failingWriter struct{ *httptest.ResponseRecorder }
+
+func (w failingWriter) Write(b []byte) (int, error) { return 0, http.ErrHandlerTimeout }
+
+type recordingBalancer struct{ targets []*ProxyTarget }
+
+func (b *recordingBalancer) Next(c *Context, targets []*ProxyTarget) *ProxyTarget {
+	b.targets = targets
+	return targets[0]
+}
+
+func TestProxyActiveOnAbort(t *testing.T) {
+	up := newUpstream("up")
+	defer up.Close()
+	balancer := &recordingBalancer{}
+	r := New()
+	r.Use(Recovery())
+	r.GET("/*path", ProxyWithConfig(ProxyConfig{Targets: []string{up.URL}, Balancer: balancer}))
+	func() {
+		defer func() {
+			if err := recover(); err != http.ErrAbortHandler {
+				t.Fatalf("copy failures should abort the handler, got %v", err)
+			}
+		}()
+		req := httptest.NewRequest("GET", "/x", nil)
+		// ReverseProxy only panics when run by a server
+		req = req.WithContext(context.WithValue(req.Context(), http.ServerContextKey, &http.Server{}))
+		r.ServeHTTP(failingWriter{httptest.NewRecorder()}, req)
+	}()
+	if active := balancer.targets[0].Active(); active != 0 {
+		t.Fatalf("aborted requests should leave the target, %d active", active)
+	}
+}
+
+func TestProxyModifyResponseError(t *testing.T) {
+	a, b := newUpstream("a"), newUpstream("b")
+	defer a.Close()
+	defer b.Close()
+	balancer := &recordingBalancer{}
+	r := New()
+	r.GET("/*path", ProxyWithConfig(ProxyConfig{
+		Targets:        []string{a.URL, b.URL},
+		Balancer:       balancer,
+		MaxFails:       1,
+		ModifyResponse: func(*http.Response) error { return errors.New("rejected") },
+	}))
+	if w := proxyGet(r, "/x"); w.Code != http.StatusBadGateway {
+		t.Fatalf("rejected responses should give 502, got %d %q", w.Code, w.Body.String())
+	}
+	if len(balancer.targets) != 2 {
+		t.Fatalf("rejected responses should not be retried, last candidates %d", len(balancer.targets))
+	}
+	if !balancer.targets[0].Healthy() {
+		t.Fatal("rejected responses should not count as target failures")
+	}
+}
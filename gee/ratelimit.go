@@ -0,0 +1,309 @@
+package gee
+
+import (
+	"encoding/binary"
+	"fmt"
+	"geecache"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateResult is the outcome of taking one request from a key's quota
+type RateResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the quota is fully available again
+	Reset time.Duration
+	// RetryAfter is the time until the next request would be allowed
+	RetryAfter time.Duration
+}
+
+// RateAlgorithm decides a request against the encoded state of a key.
+// State is kept as bytes so any RateStore can hold it.
+type RateAlgorithm interface {
+	// Take returns the state to store and the decision, state is nil for a new key
+	Take(state []byte, now time.Time) (next []byte, res RateResult)
+	// TTL is how long an untouched state stays relevant
+	TTL() time.Duration
+}
+
+type tokenBucket struct {
+	rate  float64 // tokens per second
+	burst int
+}
+
+// TokenBucket allows n requests per period on average with bursts of up to burst requests
+func TokenBucket(n int, per time.Duration, burst int) RateAlgorithm {
+	if n <= 0 || per <= 0 || burst <= 0 {
+		panic("gee: invalid token bucket parameters")
+	}
+	return &tokenBucket{rate: float64(n) / per.Seconds(), burst: burst}
+}
+
+// state: tokens (float64 bits) | last refill (unix nano)
+func (b *tokenBucket) Take(state []byte, now time.Time) ([]byte, RateResult) {
+	tokens, last := float64(b.burst), now.UnixNano()
+	if len(state) == 16 {
+		tokens = math.Float64frombits(binary.BigEndian.Uint64(state))
+		last = int64(binary.BigEndian.Uint64(state[8:]))
+	}
+	elapsed := time.Duration(now.UnixNano() - last).Seconds()
+	if elapsed > 0 {
+		tokens = math.Min(float64(b.burst), tokens+elapsed*b.rate)
+	}
+
+	res := RateResult{Limit: b.burst}
+	if tokens >= 1 {
+		tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = b.duration(1 - tokens)
+	}
+	res.Remaining = int(tokens)
+	res.Reset = b.duration(float64(b.burst) - tokens)
+
+	next := make([]byte, 16)
+	binary.BigEndian.PutUint64(next, math.Float64bits(tokens))
+	binary.BigEndian.PutUint64(next[8:], uint64(now.UnixNano()))
+	return next, res
+}
+
+func (b *tokenBucket) duration(tokens float64) time.Duration {
+	return time.Duration(tokens / b.rate * float64(time.Second))
+}
+
+func (b *tokenBucket) TTL() time.Duration {
+	return b.duration(float64(b.burst))
+}
+
+type slidingWindow struct {
+	limit  int
+	window time.Duration
+}
+
+// SlidingWindow allows limit requests in any window, approximated by
+// weighting the previous fixed window's count by its overlap
+func SlidingWindow(limit int, window time.Duration) RateAlgorithm {
+	if limit <= 0 || window <= 0 {
+		panic("gee: invalid sliding window parameters")
+	}
+	return &slidingWindow{limit: limit, window: window}
+}
+
+// state: current window start (unix nano) | previous count | current count
+func (s *slidingWindow) Take(state []byte, now time.Time) ([]byte, RateResult) {
+	start := now.Truncate(s.window).UnixNano()
+	var prev, curr uint64
+	if len(state) == 24 {
+		switch last := int64(binary.BigEndian.Uint64(state)); {
+		case last == start:
+			prev, curr = binary.BigEndian.Uint64(state[8:]), binary.BigEndian.Uint64(state[16:])
+		case last+int64(s.window) == start:
+			prev = binary.BigEndian.Uint64(state[16:])
+		}
+	}
+
+	elapsed := time.Duration(now.UnixNano() - start)
+	weight := 1 - float64(elapsed)/float64(s.window)
+	count := float64(prev)*weight + float64(curr)
+
+	res := RateResult{Limit: s.limit, Reset: s.window - elapsed}
+	if count < float64(s.limit) {
+		curr++
+		count++
+		res.Allowed = true
+	} else if prev > 0 {
+		// wait until enough of the previous window slid out
+		need := (count - float64(s.limit) + 1) / float64(prev)
+		res.RetryAfter = time.Duration(need * float64(s.window))
+		if res.RetryAfter > res.Reset {
+			res.RetryAfter = res.Reset
+		}
+	} else {
+		res.RetryAfter = res.Reset
+	}
+	if remaining := float64(s.limit) - count; remaining > 0 {
+		res.Remaining = int(remaining)
+	}
+	if prev > 0 {
+		res.Reset += s.window
+	}
+
+	next := make([]byte, 24)
+	binary.BigEndian.PutUint64(next, uint64(start))
+	binary.BigEndian.PutUint64(next[8:], prev)
+	binary.BigEndian.PutUint64(next[16:], curr)
+	return next, res
+}
+
+func (s *slidingWindow) TTL() time.Duration {
+	return 2 * s.window
+}
+
+// RateStore keeps the algorithm state of every key
+type RateStore interface {
+	// Take atomically applies alg to the state stored under key
+	Take(key string, alg RateAlgorithm, now time.Time) (RateResult, error)
+}
+
+type rateEntry struct {
+	state   []byte
+	expires time.Time
+}
+
+// MemoryRateStore keeps states in a map, expired keys are swept periodically
+type MemoryRateStore struct {
+	mu        sync.Mutex
+	entries   map[string]rateEntry
+	lastSweep time.Time
+}
+
+func NewMemoryRateStore() *MemoryRateStore {
+	return &MemoryRateStore{entries: make(map[string]rateEntry)}
+}
+
+func (s *MemoryRateStore) Take(key string, alg RateAlgorithm, now time.Time) (RateResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) > time.Minute {
+		for k, e := range s.entries {
+			if now.After(e.expires) {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	var state []byte
+	if e, ok := s.entries[key]; ok && !now.After(e.expires) {
+		state = e.state
+	}
+	next, res := alg.Take(state, now)
+	s.entries[key] = rateEntry{state: next, expires: now.Add(alg.TTL())}
+	return res, nil
+}
+
+// GroupRateStore keeps states in a geecache Group. States are written to the
+// local cache with Group.Set, so clients should be pinned to one node; the
+// group's getter may seed states and should return an error for unknown keys.
+type GroupRateStore struct {
+	mu    sync.Mutex
+	group *geecache.Group
+}
+
+func NewGroupRateStore(group *geecache.Group) *GroupRateStore {
+	return &GroupRateStore{group: group}
+}
+
+func (s *GroupRateStore) Take(key string, alg RateAlgorithm, now time.Time) (RateResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var state []byte
+	if view, err := s.group.Get(key); err == nil {
+		state = view.ByteSlice()
+	}
+	next, res := alg.Take(state, now)
+	s.group.Set(key, next)
+	return res, nil
+}
+
+// RateKeyFunc extracts the client identity a quota belongs to
+type RateKeyFunc func(c *Context) string
+
+// KeyByIP limits each client IP
+func KeyByIP() RateKeyFunc {
+	return func(c *Context) string {
+		return "ip:" + c.ClientIP()
+	}
+}
+
+// KeyByHeader limits each value of a header, e.g. an API key
+func KeyByHeader(name string) RateKeyFunc {
+	return func(c *Context) string {
+		return "header:" + c.Req.Header.Get(name)
+	}
+}
+
+// KeyByUser limits each user stored under AuthUserKey, anonymous clients by IP
+func KeyByUser() RateKeyFunc {
+	return func(c *Context) string {
+		if user, ok := c.Get(AuthUserKey); ok {
+			return fmt.Sprintf("user:%v", user)
+		}
+		return "ip:" + c.ClientIP()
+	}
+}
+
+// RateLimitConfig configures the RateLimit middleware
+type RateLimitConfig struct {
+	Algorithm RateAlgorithm
+	// Routes overrides Algorithm for route patterns, e.g. "POST /login"
+	Routes map[string]RateAlgorithm
+	// PerRoute gives every route pattern its own quota
+	PerRoute bool
+	// Store defaults to a new MemoryRateStore
+	Store RateStore
+	// Key defaults to KeyByIP
+	Key RateKeyFunc
+}
+
+// RateLimit rejects requests over quota with 429 and reports the quota
+// in RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset and Retry-After
+func RateLimit(config RateLimitConfig) HandlerFunc {
+	if config.Algorithm == nil && len(config.Routes) == 0 {
+		panic("gee: RateLimit requires an algorithm")
+	}
+	if config.Store == nil {
+		config.Store = NewMemoryRateStore()
+	}
+	if config.Key == nil {
+		config.Key = KeyByIP()
+	}
+
+	return func(c *Context) {
+		alg, route := config.Algorithm, ""
+		if config.PerRoute {
+			route = c.Method + " " + c.FullPath()
+		}
+		if a, ok := config.Routes[c.Method+" "+c.FullPath()]; ok {
+			alg, route = a, c.Method+" "+c.FullPath()
+		}
+		if alg == nil {
+			c.Next()
+			return
+		}
+
+		key := config.Key(c)
+		if route != "" {
+			key = route + "|" + key
+		}
+		res, err := config.Store.Take(key, alg, time.Now())
+		if err != nil {
+			// fail open, an unavailable store must not take the service down
+			log.Printf("rate limit store: %v", err)
+			c.Next()
+			return
+		}
+
+		c.SetHeader("RateLimit-Limit", strconv.Itoa(res.Limit))
+		c.SetHeader("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		c.SetHeader("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+		if !res.Allowed {
+			c.SetHeader("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+			c.Fail(http.StatusTooManyRequests, "Too Many Requests")
+			return
+		}
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
@@ -0,0 +1,112 @@
+package gee
+
+import (
+	"errors"
+	"geecache"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	alg := TokenBucket(1, time.Second, 2)
+	now := time.Unix(1700000000, 0)
+
+	var state []byte
+	var res RateResult
+	for i := 0; i < 2; i++ {
+		if state, res = alg.Take(state, now); !res.Allowed {
+			t.Fatalf("request %d should be allowed by the burst", i)
+		}
+	}
+	if state, res = alg.Take(state, now); res.Allowed || res.RetryAfter != time.Second {
+		t.Fatalf("bucket should be empty, got %+v", res)
+	}
+	if _, res = alg.Take(state, now.Add(time.Second)); !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("one token should be refilled after a second, got %+v", res)
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	alg := SlidingWindow(4, time.Minute)
+	start := time.Unix(1700000000, 0).Truncate(time.Minute)
+
+	var state []byte
+	var res RateResult
+	for i := 0; i < 4; i++ {
+		state, res = alg.Take(state, start.Add(30*time.Second))
+	}
+	if state, res = alg.Take(state, start.Add(30*time.Second)); res.Allowed {
+		t.Fatal("fifth request in the window should be rejected")
+	}
+	// half of the previous window still counts: 4*0.5 = 2 requests left
+	next := start.Add(90 * time.Second)
+	for i := 0; i < 2; i++ {
+		if state, res = alg.Take(state, next); !res.Allowed {
+			t.Fatalf("request %d of the next window should be allowed", i)
+		}
+	}
+	if _, res = alg.Take(state, next); res.Allowed {
+		t.Fatal("previous window should still weigh on the limit")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	r := New()
+	r.Use(RateLimit(RateLimitConfig{
+		Algorithm: TokenBucket(1, time.Hour, 2),
+		Routes:    map[string]RateAlgorithm{"POST /login": TokenBucket(1, time.Hour, 1)},
+		Key:       KeyByHeader("X-API-Key"),
+	}))
+	r.GET("/items", func(c *Context) { c.String(http.StatusOK, "items") })
+	r.POST("/login", func(c *Context) { c.String(http.StatusOK, "welcome") })
+
+	serve := func(method, path, key string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-API-Key", key)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := serve("GET", "/items", "a"); w.Code != http.StatusOK {
+			t.Fatalf("request %d should pass, got %d", i, w.Code)
+		}
+	}
+	w := serve("GET", "/items", "a")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("third request should be limited, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "3600" || w.Header().Get("RateLimit-Limit") != "2" || w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Fatalf("unexpected rate limit headers %v", w.Header())
+	}
+	if w := serve("GET", "/items", "b"); w.Code != http.StatusOK {
+		t.Fatalf("other clients should have their own quota, got %d", w.Code)
+	}
+
+	if w := serve("POST", "/login", "a"); w.Code != http.StatusOK {
+		t.Fatalf("login has its own quota, got %d", w.Code)
+	}
+	if w := serve("POST", "/login", "a"); w.Code != http.StatusTooManyRequests {
+		t.Fatalf("second login should be limited, got %d", w.Code)
+	}
+}
+
+func TestGroupRateStore(t *testing.T) {
+	group := geecache.NewGroup("ratelimit", 2<<10, geecache.GetterFunc(
+		func(key string) ([]byte, error) {
+			return nil, errors.New("no state")
+		}))
+	store := NewGroupRateStore(group)
+	alg := TokenBucket(1, time.Hour, 1)
+	now := time.Now()
+
+	if res, _ := store.Take("ip:1.2.3.4", alg, now); !res.Allowed {
+		t.Fatal("first request should be allowed")
+	}
+	if res, _ := store.Take("ip:1.2.3.4", alg, now); res.Allowed {
+		t.Fatal("state should be kept in the group")
+	}
+}
@@ -0,0 +1,66 @@
+package gee
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// responseRecorder buffers a whole response so it can be inspected,
+// stored or replayed onto the real writer later
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{header: make(http.Header)}
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.body.Write(b)
+}
+
+// Flush is a no-op, the body is sent as a whole by writeTo
+func (r *responseRecorder) Flush() {}
+
+func (r *responseRecorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// writeTo copies the buffered headers, status and body to w
+func (r *responseRecorder) writeTo(w http.ResponseWriter) {
+	header := w.Header()
+	for k, v := range r.header {
+		header[k] = v
+	}
+	w.WriteHeader(r.statusCode())
+	w.Write(r.body.Bytes())
+}
+
+// nextRecorded runs the rest of the chain into a responseRecorder, c.Writer
+// is restored even when a handler panics so Recovery reaches the client
+func nextRecorded(c *Context) *responseRecorder {
+	rec := newResponseRecorder()
+	writer := c.Writer
+	c.Writer = rec
+	defer func() { c.Writer = writer }()
+	c.Next()
+	return rec
+}
@@ -27,8 +27,14 @@ func Recovery() HandlerFunc{
 	return func(c *Context){
 		defer func(){
 			if err:=recover();err!=nil{
-				message:=fmt.Sprintf("%s",err)
-				log.Printf("%s \n\n",trace(message))
+				if err==http.ErrAbortHandler{
+					// the connection is aborted on purpose, e.g. by a proxy
+					panic(err)
+				}
+				if Mode()!=TestMode{
+					message:=fmt.Sprintf("%s",err)
+					log.Printf("%s \n\n",trace(message))
+				}
 				c.Fail(http.StatusInternalServerError,"Internal Server Error")
 			}
 		}()
@@ -0,0 +1,74 @@
+package gee
+
+import (
+	"html/template"
+	"io"
+	texttemplate "text/template"
+)
+
+// HTMLRender renders the named template for c.HTML. Implementations with a
+// SetFuncMap(template.FuncMap) method receive the engine's FuncMap.
+type HTMLRender interface {
+	Render(w io.Writer, name string, data interface{}) error
+}
+
+type funcMapRender interface {
+	SetFuncMap(funcMap template.FuncMap)
+}
+
+// TemplateRender renders templates of an html/template set by name
+type TemplateRender struct {
+	Template *template.Template
+}
+
+func (r *TemplateRender) Render(w io.Writer, name string, data interface{}) error {
+	return r.Template.ExecuteTemplate(w, name, data)
+}
+
+func (r *TemplateRender) SetFuncMap(funcMap template.FuncMap) {
+	r.Template.Funcs(funcMap)
+}
+
+// TextRender renders templates of a text/template set by name, output is
+// not escaped so it suits plain text such as emails
+type TextRender struct {
+	Template *texttemplate.Template
+}
+
+func (r *TextRender) Render(w io.Writer, name string, data interface{}) error {
+	return r.Template.ExecuteTemplate(w, name, data)
+}
+
+func (r *TextRender) SetFuncMap(funcMap template.FuncMap) {
+	r.Template.Funcs(texttemplate.FuncMap(funcMap))
+}
+
+// SetHTMLRender sets the renderer used by c.HTML
+func (engine *Engine) SetHTMLRender(r HTMLRender) {
+	engine.applyFuncMap(r)
+	engine.htmlRender = r
+}
+
+// AddHTMLRender registers a renderer used by c.HTMLWith(name, ...)
+func (engine *Engine) AddHTMLRender(name string, r HTMLRender) {
+	if engine.htmlRenders == nil {
+		engine.htmlRenders = make(map[string]HTMLRender)
+	}
+	engine.applyFuncMap(r)
+	engine.htmlRenders[name] = r
+}
+
+// HTMLRender returns the renderer registered under name, or the default
+// one for "", e.g. to render an email body outside of a request
+func (engine *Engine) HTMLRender(name string) HTMLRender {
+	if name == "" {
+		return engine.htmlRender
+	}
+	return engine.htmlRenders[name]
+}
+
+func (engine *Engine) applyFuncMap(r HTMLRender) {
+	if fr, ok := r.(funcMapRender); ok && engine.funcMap != nil {
+		fr.SetFuncMap(engine.funcMap)
+	}
+}
@@ -0,0 +1,77 @@
+package gee
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	texttemplate "text/template"
+)
+
+func TestMustacheRender(t *testing.T) {
+	r := NewMustacheRender()
+	for name, src := range map[string]string{
+		"page": `<h1>{{title}}</h1>{{! ignored }}{{>list}}{{^users}}nobody{{/users}}{{{raw}}}`,
+		"list": `<ul>{{#users}}<li>{{Name}}{{#admin}}*{{/admin}} of {{title}}</li>{{/users}}</ul>`,
+	} {
+		if err := r.Add(name, src); err != nil {
+			t.Fatal(err)
+		}
+	}
+	type user struct{ Name string }
+	data := H{
+		"title": "a<b",
+		"users": []interface{}{user{"tom"}, H{"Name": "jack", "admin": true}},
+		"raw":   "<br>",
+	}
+
+	var buf bytes.Buffer
+	if err := r.Render(&buf, "page", data); err != nil {
+		t.Fatal(err)
+	}
+	want := "<h1>a&lt;b</h1><ul><li>tom of a&lt;b</li><li>jack* of a&lt;b</li></ul><br>"
+	if buf.String() != want {
+		t.Fatalf("got  %s\nwant %s", buf.String(), want)
+	}
+
+	buf.Reset()
+	r.Render(&buf, "page", H{"users": []string{}})
+	if buf.String() != "<h1></h1><ul></ul>nobody" {
+		t.Fatalf("inverted section should render for empty lists, got %s", buf.String())
+	}
+
+	for _, src := range []string{"{{#a}}", "{{/a}}", "{{#a}}{{/b}}", "{{a"} {
+		if err := r.Add("bad", src); err == nil {
+			t.Fatalf("%q should not parse", src)
+		}
+	}
+}
+
+func TestHTMLRenderSelection(t *testing.T) {
+	mustache := NewMustacheRender()
+	mustache.Add("hi", "hi {{name}}")
+	text := &TextRender{Template: texttemplate.Must(texttemplate.New("mail").Funcs(texttemplate.FuncMap{"shout": strings.ToUpper}).Parse(`{{shout .name}} <3`))}
+
+	r := New()
+	r.SetHTMLRender(&TemplateRender{Template: template.Must(template.New("hi").Funcs(template.FuncMap{"shout": strings.ToUpper}).Parse(`{{shout .name}}`))})
+	r.AddHTMLRender("mustache", mustache)
+	r.AddHTMLRender("text", text)
+	r.SetFuncMap(template.FuncMap{"shout": func(s string) string { return s + "!" }})
+	r.GET("/default", func(c *Context) { c.HTML(http.StatusOK, "hi", H{"name": "gee"}) })
+	r.GET("/mustache", func(c *Context) { c.HTMLWith("mustache", http.StatusOK, "hi", H{"name": "gee"}) })
+
+	for path, want := range map[string]string{"/default": "gee!", "/mustache": "hi gee"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Body.String() != want {
+			t.Fatalf("%s = %q, want %q", path, w.Body.String(), want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := r.HTMLRender("text").Render(&buf, "mail", H{"name": "gee"}); err != nil || buf.String() != "gee! <3" {
+		t.Fatalf("text render = %q, %v", buf.String(), err)
+	}
+}
@@ -0,0 +1,56 @@
+package gee
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// RequestIDKey is the context key holding the request ID
+const RequestIDKey = "requestID"
+
+// RequestIDConfig configures the RequestID middleware
+type RequestIDConfig struct {
+	// Header defaults to X-Request-ID
+	Header string
+	// Generator defaults to 16 random bytes in hex
+	Generator func() string
+}
+
+// RequestID returns a middleware with the default RequestIDConfig
+func RequestID() HandlerFunc {
+	return RequestIDWithConfig(RequestIDConfig{})
+}
+
+// RequestIDWithConfig reuses the ID sent by the client or generates one,
+// stores it under RequestIDKey and echoes it in the response
+func RequestIDWithConfig(config RequestIDConfig) HandlerFunc {
+	if config.Header == "" {
+		config.Header = "X-Request-ID"
+	}
+	if config.Generator == nil {
+		config.Generator = func() string { return randomHex(16) }
+	}
+	return func(c *Context) {
+		id := c.Req.Header.Get(config.Header)
+		if id == "" || len(id) > 128 {
+			id = config.Generator()
+		}
+		c.Set(RequestIDKey, id)
+		c.SetHeader(config.Header, id)
+		c.Next()
+	}
+}
+
+// RequestID returns the ID set by the RequestID middleware
+func (c *Context) RequestID() string {
+	id, _ := c.Keys[RequestIDKey].(string)
+	return id
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -3,12 +3,14 @@ package gee
 import (
 	// "fmt"
 	"net/http"
+	"path"
 	"strings"
 )
 
 type router struct{
 	roots map[string]*node
 	handlers map[string]HandlerFunc
+	hosts []*virtualHost // routers of the virtual hosts, exact hosts first
 }
 
 // roots key eg, roots['GET'] roots['POST']
@@ -60,7 +62,7 @@ func (r *router) getRoute(method string,path string)(*node,map[string]string){
 	}
 	n:=root.search(searchParts,0)
 	if n!=nil{
-		parts:=parsePattern(n.pattern)
+		parts:=parsePattern(n.route(path))
 		for index,part:=range parts{
 			if part[0]==':'{
 				params[part[1:]] = searchParts[index]
@@ -86,16 +88,149 @@ func (r *router) getRoutes(method string) []*node {
 	return nodes
 }
 
-func (r *router)handle(c *Context) {
-	n, params := r.getRoute(c.Method, c.Path)
-	if n!=nil {	
-		key:=c.Method+"-"+n.pattern
+// getHostRoute finds the route of a request, trying the virtual hosts
+// matching host before the routes registered without a host
+func (r *router) getHostRoute(method, host, path string) (*router, *node, map[string]string) {
+	for _, vh := range r.hosts {
+		hostParams, ok := matchHost(vh.labels, host)
+		if !ok {
+			continue
+		}
+		if n, params := vh.router.getRoute(method, path); n != nil {
+			for key, value := range hostParams {
+				if _, ok := params[key]; !ok {
+					params[key] = value
+				}
+			}
+			return vh.router, n, params
+		}
+	}
+	n, params := r.getRoute(method, path)
+	return r, n, params
+}
+
+// handle serves c with the route found by getHostRoute
+func (r *router)handle(c *Context, rt *router, n *node, params map[string]string) {
+	if c.engine.RedirectFixedPath && cleanPath(c.Path) != c.Path {
+		// unclean paths are only served through a redirect to the clean one
+		n = nil
+	}
+	if location, ok := r.redirectPath(c, n); ok && safeLocation(location) {
+		c.handlers=append(c.handlers,func(c *Context){
+			redirectFixed(c, location)
+		})
+	}else if n!=nil {	
+		pattern:=n.route(c.Path)
+		key:=c.Method+"-"+pattern
 		c.Params=params
-		c.handlers=append(c.handlers,r.handlers[key])
+		c.fullPath=pattern
+		c.handlers=append(c.handlers,rt.handlers[key])
 	}else{
 		c.handlers=append(c.handlers,func(c *Context){
 			c.String(http.StatusNotFound, "404 NOT FOUND: %s \n", c.Path)
 		})
 	}
 	c.Next()
-}
\ No newline at end of file
+}
+
+// redirectPath returns where a request should be redirected to: the
+// canonical spelling of its route when the path differs from it in a
+// trailing slash (RedirectTrailingSlash) or in empty segments
+// (RedirectFixedPath), or, with RedirectFixedPath, the route found by
+// cleaning the path and ignoring case when nothing matched or the path
+// was not clean
+func (r *router) redirectPath(c *Context, n *node) (string, bool) {
+	engine := c.engine
+	if n != nil {
+		pattern := n.route(c.Path)
+		if strings.Contains(pattern, "*") {
+			return "", false
+		}
+		location := buildPath(pattern, parsePattern(c.Path))
+		if location == c.Path {
+			return "", false
+		}
+		if strings.TrimSuffix(location, "/") == strings.TrimSuffix(c.Path, "/") {
+			return location, engine.RedirectTrailingSlash
+		}
+		return location, engine.RedirectFixedPath
+	}
+	if !engine.RedirectFixedPath || c.Method == http.MethodConnect {
+		return "", false
+	}
+	parts := parsePattern(cleanPath(c.Path))
+	routers := make([]*router, 0, len(r.hosts)+1)
+	for _, vh := range r.hosts {
+		if _, ok := matchHost(vh.labels, c.Req.Host); ok {
+			routers = append(routers, vh.router)
+		}
+	}
+	for _, rt := range append(routers, r) {
+		if root, ok := rt.roots[c.Method]; ok {
+			if n, fixed := root.searchFold(parts, 0); n != nil {
+				return buildPath(n.route(c.Path), fixed), true
+			}
+		}
+	}
+	return "", false
+}
+
+// cleanPath resolves the dot and empty segments of p, keeping its
+// trailing slash
+func cleanPath(p string) string {
+	cleaned := path.Clean("/" + p)
+	if strings.HasSuffix(p, "/") && cleaned != "/" {
+		cleaned += "/"
+	}
+	return cleaned
+}
+
+// buildPath spells the path matched by pattern, keeping the param values
+// from parts and the trailing slash of pattern
+func buildPath(pattern string, parts []string) string {
+	patternParts := parsePattern(pattern)
+	segments := make([]string, 0, len(patternParts))
+	for i, part := range patternParts {
+		switch part[0] {
+		case ':':
+			segments = append(segments, parts[i])
+		case '*':
+			segments = append(segments, parts[i:]...)
+		default:
+			segments = append(segments, part)
+		}
+	}
+	location := "/" + strings.Join(segments, "/")
+	if len(segments) > 0 && strings.HasSuffix(pattern, "/") {
+		location += "/"
+	}
+	return location
+}
+
+// safeLocation reports whether a location built from the request path
+// stays on this host. Browsers read a leading "//" or "/\" as another
+// host and drop control characters, so such paths are not redirected.
+func safeLocation(location string) bool {
+	if strings.HasPrefix(location, "//") || strings.Contains(location, "\\") {
+		return false
+	}
+	for i := 0; i < len(location); i++ {
+		if location[i] < 0x20 || location[i] == 0x7f {
+			return false
+		}
+	}
+	return true
+}
+
+// redirectFixed redirects permanently, with 308 for methods other than GET
+// and HEAD so clients keep the method and body
+func redirectFixed(c *Context, location string) {
+	code := http.StatusMovedPermanently
+	if c.Method != http.MethodGet && c.Method != http.MethodHead {
+		code = http.StatusPermanentRedirect
+	}
+	if c.Req.URL.RawQuery != "" {
+		location += "?" + c.Req.URL.RawQuery
+	}
+	c.Redirect(code, location)
+}
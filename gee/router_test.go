@@ -3,6 +3,8 @@ package gee
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 )
@@ -44,4 +46,96 @@ func TestGetRoute(t *testing.T) {
 
 	fmt.Printf("matched path: %s, params['name']: %s\n", n.pattern, ps["name"])
 
-}
\ No newline at end of file
+}
+func TestRedirectPaths(t *testing.T) {
+	r := New()
+	r.RedirectFixedPath = true
+	ok := func(c *Context) { c.String(http.StatusOK, c.FullPath()) }
+	r.GET("/hello", ok)
+	r.GET("/dir/", ok)
+	r.POST("/users/:id/Edit", ok)
+	r.GET("/a/:x/b", ok)
+	r.GET("/b", ok)
+	r.GET("/static/*filepath", ok)
+
+	tests := []struct {
+		method, path string
+		code         int
+		location     string
+	}{
+		{"GET", "/hello", http.StatusOK, ""},
+		{"GET", "/hello/?a=1", http.StatusMovedPermanently, "/hello?a=1"},
+		{"GET", "/dir", http.StatusMovedPermanently, "/dir/"},
+		{"GET", "//hello", http.StatusMovedPermanently, "/hello"},
+		{"GET", "/x/../HELLO", http.StatusMovedPermanently, "/hello"},
+		{"POST", "/USERS/Tom/edit/", http.StatusPermanentRedirect, "/users/Tom/Edit"},
+		{"GET", "/static/a/", http.StatusOK, ""},
+		{"GET", "/a/../b", http.StatusMovedPermanently, "/b"},
+		{"GET", "/a/./b", http.StatusNotFound, ""},
+		{"GET", "/a/x/b", http.StatusOK, ""},
+		{"GET", "/missing", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.code || w.Header().Get("Location") != tt.location {
+			t.Fatalf("%s %s = %d %q, want %d %q", tt.method, tt.path, w.Code, w.Header().Get("Location"), tt.code, tt.location)
+		}
+	}
+
+	r.RedirectTrailingSlash, r.RedirectFixedPath = false, false
+	for _, p := range []string{"/hello/", "//hello"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", p, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s should be served when redirects are disabled, got %d", p, w.Code)
+		}
+	}
+}
+
+func TestRedirectOpenRedirect(t *testing.T) {
+	r := New()
+	r.RedirectFixedPath = true
+	r.GET("/:name", func(c *Context) { c.String(http.StatusOK, c.Param("name")) })
+	for _, p := range []string{"/%5Cevil.com/", "/%5C%5Cevil.com/", "/a%0Db/", "/%09evil.com/"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", p, nil))
+		if location := w.Header().Get("Location"); location != "" {
+			t.Fatalf("%s redirected to %q", p, location)
+		}
+	}
+}
+
+func TestTrailingSlashRoutes(t *testing.T) {
+	r := New()
+	r.GET("/hello", func(c *Context) { c.String(http.StatusOK, "without") })
+	r.GET("/hello/", func(c *Context) { c.String(http.StatusOK, "with") })
+	r.GET("/only/", func(c *Context) { c.String(http.StatusOK, "only") })
+
+	for _, redirect := range []bool{true, false} {
+		r.RedirectTrailingSlash = redirect
+		for path, want := range map[string]string{"/hello": "without", "/hello/": "with", "/only/": "only"} {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+			if w.Code != http.StatusOK || w.Body.String() != want {
+				t.Fatalf("%s = %d %q, want %q", path, w.Code, w.Body.String(), want)
+			}
+		}
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/only", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "only" {
+		t.Fatalf("/only should be served by /only/ when redirects are disabled, got %d", w.Code)
+	}
+}
+
+func TestStaticAndParamSiblings(t *testing.T) {
+	r := newRouter()
+	r.addRoute("GET", "/a/:x", nil)
+	r.addRoute("GET", "/a/b", nil)
+	for path, want := range map[string]string{"/a/b": "/a/b", "/a/c": "/a/:x"} {
+		if n, _ := r.getRoute("GET", path); n == nil || n.pattern != want {
+			t.Fatalf("%s matched %v, want %s", path, n, want)
+		}
+	}
+}
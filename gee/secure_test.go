@@ -0,0 +1,67 @@
+package gee
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestSecure(t *testing.T) {
+	config := DefaultSecureConfig()
+	config.ContentSecurityPolicy = "script-src 'nonce-{nonce}'"
+	r := New()
+	r.Use(SecureWithConfig(config))
+	r.SetHTMLRender(&TemplateRender{Template: template.Must(template.New("page").Parse(`<script nonce="{{.cspNonce}}"></script>`))})
+	r.GET("/", func(c *Context) { c.HTML(http.StatusOK, "page", H{}) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	h := w.Header()
+	if h.Get("Strict-Transport-Security") != "max-age=31536000; includeSubDomains" ||
+		h.Get("X-Frame-Options") != "DENY" || h.Get("X-Content-Type-Options") != "nosniff" {
+		t.Fatalf("unexpected security headers %v", h)
+	}
+	csp := h.Get("Content-Security-Policy")
+	nonce := strings.TrimSuffix(strings.TrimPrefix(csp, "script-src 'nonce-"), "'")
+	if nonce == "" || nonce == csp {
+		t.Fatalf("nonce missing from %q", csp)
+	}
+	if w.Body.String() != `<script nonce="`+nonce+`"></script>` {
+		t.Fatalf("template should see the nonce, got %s", w.Body.String())
+	}
+}
+
+func TestCSRF(t *testing.T) {
+	r := New()
+	r.Use(CSRF())
+	r.SetHTMLRender(&TemplateRender{Template: template.Must(template.New("form").Parse(`{{.csrfToken}}`))})
+	r.GET("/form", func(c *Context) { c.HTML(http.StatusOK, "form", H{}) })
+	r.POST("/form", func(c *Context) { c.String(http.StatusOK, "saved") })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/form", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value != w.Body.String() {
+		t.Fatalf("token should be issued in a cookie and rendered, got %v %q", cookies, w.Body.String())
+	}
+	cookie := cookies[0]
+
+	post := func(token string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/form", strings.NewReader(url.Values{"_csrf": {token}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.AddCookie(cookie)
+		r.ServeHTTP(w, req)
+		return w
+	}
+	if w := post(cookie.Value); w.Code != http.StatusOK || w.Body.String() != "saved" {
+		t.Fatalf("valid token should pass, got %d", w.Code)
+	}
+	if w := post("forged"); w.Code != http.StatusForbidden {
+		t.Fatalf("invalid token should be rejected, got %d", w.Code)
+	}
+}
@@ -0,0 +1,285 @@
+package gee
+
+import (
+	"encoding/json"
+	"geecache"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SessionKey is the context key holding the *Session
+const SessionKey = "session"
+
+// Session holds per-client values between requests. Values stored by the
+// cookie and geecache stores round-trip through encoding/json.
+type Session struct {
+	ID      string
+	Values  map[string]interface{}
+	IsNew   bool
+	changed bool
+	deleted bool
+}
+
+func newSession() *Session {
+	return &Session{ID: randomBase64(32), Values: make(map[string]interface{}), IsNew: true}
+}
+
+func (s *Session) Get(key string) interface{} {
+	return s.Values[key]
+}
+
+func (s *Session) Set(key string, value interface{}) {
+	s.Values[key] = value
+	s.changed = true
+}
+
+func (s *Session) Delete(key string) {
+	delete(s.Values, key)
+	s.changed = true
+}
+
+// Destroy removes the session from the store and the client
+func (s *Session) Destroy() {
+	s.Values = make(map[string]interface{})
+	s.deleted = true
+}
+
+// SessionStore loads and persists sessions. The cookie value is whatever
+// the store needs to find the session again: the ID or the whole session.
+type SessionStore interface {
+	// Load returns the session of a cookie value, or a new session
+	Load(c *Context, cookie string) (*Session, error)
+	// Save persists s and returns the value of the session cookie
+	Save(c *Context, s *Session) (cookie string, err error)
+	Delete(c *Context, s *Session) error
+}
+
+// CookieSessionStore keeps the whole session in the client's cookie,
+// signed and optionally encrypted with the given keys
+type CookieSessionStore struct {
+	name  string
+	codec *SecureCookie
+}
+
+// NewCookieSessionStore uses name as the authenticated cookie name, it must
+// match the name given to Sessions
+func NewCookieSessionStore(name string, keys ...CookieKey) *CookieSessionStore {
+	return &CookieSessionStore{name: name, codec: NewSecureCookie(keys...)}
+}
+
+func (s *CookieSessionStore) Load(c *Context, cookie string) (*Session, error) {
+	session := newSession()
+	if cookie == "" {
+		return session, nil
+	}
+	data, err := s.codec.Decode(s.name, cookie)
+	if err != nil {
+		return session, nil
+	}
+	if err := json.Unmarshal(data, &session.Values); err != nil {
+		return newSession(), nil
+	}
+	session.IsNew = false
+	return session, nil
+}
+
+func (s *CookieSessionStore) Save(c *Context, session *Session) (string, error) {
+	data, err := json.Marshal(session.Values)
+	if err != nil {
+		return "", err
+	}
+	return s.codec.Encode(s.name, data)
+}
+
+func (s *CookieSessionStore) Delete(c *Context, session *Session) error {
+	return nil
+}
+
+type memorySession struct {
+	values  map[string]interface{}
+	expires time.Time
+}
+
+// MemorySessionStore keeps sessions in process, the cookie holds the ID
+type MemorySessionStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	sessions  map[string]memorySession
+	lastSweep time.Time
+}
+
+// NewMemorySessionStore expires sessions neither loaded nor saved for ttl
+func NewMemorySessionStore(ttl time.Duration) *MemorySessionStore {
+	return &MemorySessionStore{ttl: ttl, sessions: make(map[string]memorySession)}
+}
+
+func (s *MemorySessionStore) Load(c *Context, id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.sweep(now)
+	if stored, ok := s.sessions[id]; ok && now.Before(stored.expires) {
+		stored.expires = now.Add(s.ttl)
+		s.sessions[id] = stored
+		values := make(map[string]interface{}, len(stored.values))
+		for k, v := range stored.values {
+			values[k] = v
+		}
+		return &Session{ID: id, Values: values}, nil
+	}
+	return newSession(), nil
+}
+
+// sweep drops the expired sessions, at most once a minute
+func (s *MemorySessionStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) <= time.Minute {
+		return
+	}
+	for id, stored := range s.sessions {
+		if !now.Before(stored.expires) {
+			delete(s.sessions, id)
+		}
+	}
+	s.lastSweep = now
+}
+
+func (s *MemorySessionStore) Save(c *Context, session *Session) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.sweep(now)
+	s.sessions[session.ID] = memorySession{values: session.Values, expires: now.Add(s.ttl)}
+	return session.ID, nil
+}
+
+func (s *MemorySessionStore) Delete(c *Context, session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, session.ID)
+	return nil
+}
+
+type groupSession struct {
+	Values  map[string]interface{} `json:"values"`
+	Expires time.Time              `json:"expires"`
+}
+
+// GroupSessionStore keeps sessions in a geecache Group, the cookie holds
+// the ID. Sessions are written with Group.Set to the local cache, so they
+// are only visible to peers that own the ID. The group's getter should
+// return an error for unknown IDs.
+type GroupSessionStore struct {
+	group *geecache.Group
+	ttl   time.Duration
+}
+
+func NewGroupSessionStore(group *geecache.Group, ttl time.Duration) *GroupSessionStore {
+	return &GroupSessionStore{group: group, ttl: ttl}
+}
+
+func (s *GroupSessionStore) Load(c *Context, id string) (*Session, error) {
+	if id == "" {
+		return newSession(), nil
+	}
+	view, err := s.group.Get(id)
+	if err != nil || view.Len() == 0 {
+		return newSession(), nil
+	}
+	var stored groupSession
+	if err := json.Unmarshal(view.ByteSlice(), &stored); err != nil || time.Now().After(stored.Expires) {
+		return newSession(), nil
+	}
+	if stored.Values == nil {
+		stored.Values = make(map[string]interface{})
+	}
+	return &Session{ID: id, Values: stored.Values}, nil
+}
+
+func (s *GroupSessionStore) Save(c *Context, session *Session) (string, error) {
+	data, err := json.Marshal(groupSession{Values: session.Values, Expires: time.Now().Add(s.ttl)})
+	if err != nil {
+		return "", err
+	}
+	s.group.Set(session.ID, data)
+	return session.ID, nil
+}
+
+// Delete leaves an empty value behind as geecache can not remove keys
+func (s *GroupSessionStore) Delete(c *Context, session *Session) error {
+	s.group.Set(session.ID, nil)
+	return nil
+}
+
+// Sessions loads the session named by the cookie before the handlers run
+// and saves it before the response headers are written
+func Sessions(name string, store SessionStore, opts CookieOptions) HandlerFunc {
+	return func(c *Context) {
+		cookie, _ := c.Cookie(name)
+		session, err := store.Load(c, cookie)
+		if err != nil {
+			c.Fail(http.StatusInternalServerError, "Internal Server Error")
+			return
+		}
+		c.Set(SessionKey, session)
+
+		sw := &sessionWriter{ResponseWriter: c.Writer}
+		sw.save = func() {
+			switch {
+			case session.deleted:
+				store.Delete(c, session)
+				deleted := opts
+				deleted.MaxAge = -1
+				c.SetCookie(name, "", deleted)
+			case session.changed:
+				if value, err := store.Save(c, session); err == nil {
+					c.SetCookie(name, value, opts)
+				}
+			}
+		}
+		c.Writer = sw
+		defer func() {
+			sw.saveOnce()
+			c.Writer = sw.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// Session returns the session loaded by the Sessions middleware, or nil
+func (c *Context) Session() *Session {
+	session, _ := c.Keys[SessionKey].(*Session)
+	return session
+}
+
+// sessionWriter saves the session right before the headers are sent,
+// Set-Cookie would be lost if it were added after the handler wrote
+type sessionWriter struct {
+	http.ResponseWriter
+	save  func()
+	saved bool
+}
+
+func (w *sessionWriter) saveOnce() {
+	if !w.saved {
+		w.saved = true
+		w.save()
+	}
+}
+
+func (w *sessionWriter) WriteHeader(code int) {
+	w.saveOnce()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *sessionWriter) Write(b []byte) (int, error) {
+	w.saveOnce()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *sessionWriter) Flush() {
+	w.saveOnce()
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
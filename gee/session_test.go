@@ -0,0 +1,115 @@
+package gee
+
+import (
+	"errors"
+	"geecache"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSecureCookie(t *testing.T) {
+	oldKey := CookieKey{HashKey: []byte("old-hash"), BlockKey: []byte("0123456789abcdef")}
+	newKey := CookieKey{HashKey: []byte("new-hash")}
+
+	old := NewSecureCookie(oldKey)
+	encoded, err := old.Encode("session", []byte("tom"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := old.Decode("session", encoded); err != nil || string(value) != "tom" {
+		t.Fatalf("Decode = %q, %v", value, err)
+	}
+	if _, err := old.Decode("other", encoded); err != ErrCookieInvalid {
+		t.Fatal("value must be bound to the cookie name")
+	}
+
+	rotated := NewSecureCookie(newKey, oldKey)
+	if value, err := rotated.Decode("session", encoded); err != nil || string(value) != "tom" {
+		t.Fatal("values encoded with a rotated out key should still decode")
+	}
+	if _, err := NewSecureCookie(newKey).Decode("session", encoded); err != ErrCookieInvalid {
+		t.Fatal("values of unknown keys must be rejected")
+	}
+
+	// issued times have second precision, so they are already older than 1ns
+	rotated.MaxAge = time.Nanosecond
+	if _, err := rotated.Decode("session", encoded); err != ErrCookieExpired {
+		t.Fatalf("expected ErrCookieExpired, got %v", err)
+	}
+}
+
+func testSessionStore(t *testing.T, store SessionStore) {
+	r := New()
+	r.Use(Sessions("gee_session", store, CookieOptions{HttpOnly: true}))
+	r.GET("/visit", func(c *Context) {
+		session := c.Session()
+		visits, _ := session.Get("visits").(float64)
+		session.Set("visits", visits+1)
+		c.String(http.StatusOK, "%v", visits+1)
+	})
+	r.GET("/logout", func(c *Context) {
+		c.Session().Destroy()
+		c.String(http.StatusOK, "bye")
+	})
+
+	var cookie *http.Cookie
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		r.ServeHTTP(w, req)
+		if cookies := w.Result().Cookies(); len(cookies) > 0 {
+			cookie = cookies[0]
+		}
+		return w
+	}
+
+	for i, want := range []string{"1", "2", "3"} {
+		if w := serve("/visit"); w.Body.String() != want {
+			t.Fatalf("visit %d = %s, want %s", i, w.Body.String(), want)
+		}
+	}
+	serve("/logout")
+	if cookie.MaxAge >= 0 {
+		t.Fatal("logout should delete the session cookie")
+	}
+	cookie = nil
+	if w := serve("/visit"); w.Body.String() != "1" {
+		t.Fatalf("a new session should start, got %s", w.Body.String())
+	}
+}
+
+func TestSessionStores(t *testing.T) {
+	t.Run("cookie", func(t *testing.T) {
+		testSessionStore(t, NewCookieSessionStore("gee_session", CookieKey{HashKey: []byte("k")}))
+	})
+	t.Run("memory", func(t *testing.T) {
+		testSessionStore(t, NewMemorySessionStore(time.Hour))
+	})
+	t.Run("geecache", func(t *testing.T) {
+		group := geecache.NewGroup("sessions", 2<<10, geecache.GetterFunc(
+			func(key string) ([]byte, error) {
+				return nil, errors.New("no session")
+			}))
+		testSessionStore(t, NewGroupSessionStore(group, time.Hour))
+	})
+}
+
+func TestMemorySessionStoreExpiry(t *testing.T) {
+	s := NewMemorySessionStore(time.Hour)
+	now := time.Now()
+	s.sessions["old"] = memorySession{expires: now.Add(-time.Second)}
+	s.sessions["read"] = memorySession{values: map[string]interface{}{"a": 1}, expires: now.Add(time.Minute)}
+
+	session, _ := s.Load(nil, "read")
+	if session.ID != "read" || !s.sessions["read"].expires.After(now.Add(time.Minute)) {
+		t.Fatal("loading a session should extend it")
+	}
+	if _, ok := s.sessions["old"]; ok {
+		t.Fatal("expired sessions should be swept")
+	}
+}
@@ -0,0 +1,196 @@
+package gee
+
+import (
+	"fmt"
+	"html/template"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StaticOptions configures Static, StaticFS and StaticFile
+type StaticOptions struct {
+	// Browse enables directory listings
+	Browse bool
+	// Index is served for directories, defaults to "index.html"
+	Index string
+	// SPA serves the root Index for paths that do not exist, so a single
+	// page app can handle its own routes
+	SPA bool
+	// MaxAge sets Cache-Control, 0 leaves it to the client
+	MaxAge time.Duration
+	// Precompressed serves "<file>.br" or "<file>.gz" when it exists and
+	// the client accepts the encoding
+	Precompressed bool
+}
+
+var precompressed = []struct{ ext, encoding string }{
+	{".br", "br"},
+	{".gz", "gzip"},
+}
+
+// create static handler
+func (group *RouterGroup) createStaticHandler(fs http.FileSystem, opts StaticOptions) HandlerFunc {
+	if opts.Index == "" {
+		opts.Index = "index.html"
+	}
+	return func(c *Context) {
+		name := path.Clean("/" + c.Param("filepath"))
+		if serveStatic(c, fs, name, opts) {
+			return
+		}
+		if opts.SPA && path.Ext(name) == "" && serveStatic(c, fs, "/"+opts.Index, opts) {
+			return
+		}
+		c.String(http.StatusNotFound, "404 NOT FOUND: %s \n", c.Path)
+	}
+}
+
+// serveStatic reports false when name does not exist or can not be shown
+func serveStatic(c *Context, fs http.FileSystem, name string, opts StaticOptions) bool {
+	f, err := fs.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	if info.IsDir() {
+		index := path.Join(name, opts.Index)
+		if indexFile, err := fs.Open(index); err == nil {
+			indexFile.Close()
+			return serveStatic(c, fs, index, opts)
+		}
+		if !opts.Browse {
+			return false
+		}
+		if !strings.HasSuffix(c.Req.URL.Path, "/") {
+			location := c.Req.URL.Path + "/"
+			if c.Req.URL.RawQuery != "" {
+				location += "?" + c.Req.URL.RawQuery
+			}
+			c.Redirect(http.StatusMovedPermanently, location)
+			return true
+		}
+		listDir(c, f)
+		return true
+	}
+
+	header := c.Writer.Header()
+	if opts.MaxAge > 0 {
+		header.Set("Cache-Control", "public, max-age="+strconv.FormatInt(int64(opts.MaxAge/time.Second), 10))
+	}
+
+	content, stat := f, info
+	if opts.Precompressed {
+		header.Add("Vary", "Accept-Encoding")
+		accept := c.Req.Header.Get("Accept-Encoding")
+		for _, p := range precompressed {
+			if !strings.Contains(accept, p.encoding) {
+				continue
+			}
+			cf, err := fs.Open(name + p.ext)
+			if err != nil {
+				continue
+			}
+			defer cf.Close()
+			if cinfo, err := cf.Stat(); err == nil && !cinfo.IsDir() {
+				content, stat = cf, cinfo
+				header.Set("Content-Encoding", p.encoding)
+				if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+					header.Set("Content-Type", ctype)
+				}
+				break
+			}
+		}
+	}
+	if header.Get("ETag") == "" {
+		header.Set("ETag", fmt.Sprintf(`W/"%x-%x"`, stat.Size(), stat.ModTime().UnixNano()))
+	}
+	c.StatusCode = http.StatusOK
+	http.ServeContent(c.Writer, c.Req, info.Name(), stat.ModTime(), content)
+	return true
+}
+
+func listDir(c *Context, dir http.File) {
+	entries, err := dir.Readdir(-1)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error reading directory")
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var b strings.Builder
+	b.WriteString("<pre>\n")
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			name += "/"
+		}
+		fmt.Fprintf(&b, "<a href=\"%s\">%s</a>\n", template.HTMLEscapeString(name), template.HTMLEscapeString(name))
+	}
+	b.WriteString("</pre>\n")
+	c.SetHeader("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	c.Writer.Write([]byte(b.String()))
+}
+
+func staticOptions(opts []StaticOptions) StaticOptions {
+	if len(opts) > 0 {
+		return opts[0]
+	}
+	return StaticOptions{}
+}
+
+// serve static files
+func (group *RouterGroup) Static(relativePath string, root string, opts ...StaticOptions) {
+	group.staticFileSystem(relativePath, http.Dir(root), opts...)
+}
+
+// StaticFS serves the files of fsys, e.g. an embed.FS, under relativePath.
+// Use fs.Sub to serve a subdirectory:
+//
+//	//go:embed static
+//	var assets embed.FS
+//	sub, _ := fs.Sub(assets, "static")
+//	r.StaticFS("/assets", sub)
+func (group *RouterGroup) StaticFS(relativePath string, fsys fs.FS, opts ...StaticOptions) {
+	group.staticFileSystem(relativePath, http.FS(fsys), opts...)
+}
+
+func (group *RouterGroup) staticFileSystem(relativePath string, fs http.FileSystem, opts ...StaticOptions) {
+	if strings.Contains(relativePath, ":") || strings.Contains(relativePath, "*") {
+		panic("gee: URL parameters can not be used when serving a static folder")
+	}
+	handler := group.createStaticHandler(fs, staticOptions(opts))
+	urlPattern := path.Join(relativePath, "/*filepath")
+	// Register GET and HEAD handlers
+	group.GET(urlPattern, handler)
+	group.HEAD(urlPattern, handler)
+}
+
+// StaticFile serves a single file of the local file system
+func (group *RouterGroup) StaticFile(relativePath string, file string, opts ...StaticOptions) {
+	if strings.Contains(relativePath, ":") || strings.Contains(relativePath, "*") {
+		panic("gee: URL parameters can not be used when serving a static file")
+	}
+	fs := http.Dir(filepath.Dir(file))
+	name := "/" + filepath.Base(file)
+	options := staticOptions(opts)
+	handler := func(c *Context) {
+		if !serveStatic(c, fs, name, options) {
+			c.String(http.StatusNotFound, "404 NOT FOUND: %s \n", c.Path)
+		}
+	}
+	group.GET(relativePath, handler)
+	group.HEAD(relativePath, handler)
+}
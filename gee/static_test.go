@@ -0,0 +1,110 @@
+package gee
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func newStaticDir(t *testing.T) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		"index.html":     "home",
+		"app.js":         "console.log('gee')",
+		"app.js.gz":      "gzipped",
+		"docs/guide.txt": "guide",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(p), 0755)
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestStatic(t *testing.T) {
+	dir := newStaticDir(t)
+	r := New()
+	r.Static("/assets", dir)
+	r.Static("/spa", dir, StaticOptions{SPA: true, MaxAge: time.Hour, Precompressed: true})
+	r.Static("/browse", dir, StaticOptions{Browse: true, Index: "missing.html"})
+	r.StaticFile("/favicon.txt", filepath.Join(dir, "docs", "guide.txt"))
+
+	serve := func(path string, header ...string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("/assets/app.js")
+	if w.Code != http.StatusOK || w.Body.String() != "console.log('gee')" || w.Header().Get("ETag") == "" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+	if w2 := serve("/assets/app.js", "If-None-Match", w.Header().Get("ETag")); w2.Code != http.StatusNotModified {
+		t.Fatalf("matching ETag should yield 304, got %d", w2.Code)
+	}
+	if w = serve("/assets/docs"); w.Code != http.StatusNotFound {
+		t.Fatalf("directory listing should be disabled by default, got %d", w.Code)
+	}
+	if w = serve("/assets/nope.js"); w.Code != http.StatusNotFound || w.Body.Len() == 0 {
+		t.Fatalf("missing files should get a 404 body, got %d %q", w.Code, w.Body.String())
+	}
+	if w = serve("/assets/../../etc/passwd"); w.Code != http.StatusNotFound {
+		t.Fatalf("path traversal should be rejected, got %d", w.Code)
+	}
+
+	if w = serve("/spa/users/1"); w.Body.String() != "home" || w.Header().Get("Cache-Control") != "public, max-age=3600" {
+		t.Fatalf("SPA fallback should serve index.html, got %q", w.Body.String())
+	}
+	w = serve("/spa/app.js", "Accept-Encoding", "gzip")
+	if w.Body.String() != "gzipped" || w.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("precompressed variant should be served, got %q", w.Body.String())
+	}
+	if ctype := w.Header().Get("Content-Type"); ctype != "text/javascript; charset=utf-8" {
+		t.Fatalf("precompressed variant should keep the original type, got %q", ctype)
+	}
+
+	if w = serve("/browse/docs/"); w.Code != http.StatusOK || w.Body.String() != "<pre>\n<a href=\"guide.txt\">guide.txt</a>\n</pre>\n" {
+		t.Fatalf("unexpected listing %d %q", w.Code, w.Body.String())
+	}
+	if w = serve("/favicon.txt"); w.Body.String() != "guide" {
+		t.Fatalf("StaticFile should serve the file, got %q", w.Body.String())
+	}
+}
+
+func TestStaticFS(t *testing.T) {
+	assets := fstest.MapFS{
+		"static/css/1.css":  {Data: []byte("p {}")},
+		"templates/hi.tmpl": {Data: []byte(`hi {{.name}}`)},
+	}
+	sub, err := fs.Sub(assets, "static")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := New()
+	r.StaticFS("/assets", sub)
+	r.LoadHTMLFS(assets, "templates/*.tmpl")
+	r.GET("/hi", func(c *Context) { c.HTML(http.StatusOK, "hi.tmpl", H{"name": "gee"}) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/assets/css/1.css", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "p {}" {
+		t.Fatalf("unexpected response %d %q", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/hi", nil))
+	if w.Body.String() != "hi gee" {
+		t.Fatalf("template from fs.FS should render, got %q", w.Body.String())
+	}
+}
@@ -0,0 +1,72 @@
+package gee
+
+import (
+	"context"
+	"net/http"
+)
+
+type contextKey struct{}
+
+// ContextFromRequest returns the gee Context a request passed through, so
+// net/http code called from FromStd can reach its Keys and Params
+func ContextFromRequest(req *http.Request) (*Context, bool) {
+	c, ok := req.Context().Value(contextKey{}).(*Context)
+	return c, ok
+}
+
+// FromStd turns a net/http middleware into a HandlerFunc. The rest of the
+// chain runs when the middleware calls its next handler, with the writer
+// and request it passes on, and is aborted when it does not.
+func FromStd(mw func(http.Handler) http.Handler) HandlerFunc {
+	return func(c *Context) {
+		writer, req := c.Writer, c.Req
+		called := false
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			c.Writer, c.Req = w, r
+			c.Next()
+		})
+		mw(next).ServeHTTP(&statusWriter{ResponseWriter: writer, c: c}, withContext(req, c))
+		c.Writer, c.Req = writer, req
+		if !called {
+			c.Abort()
+		}
+	}
+}
+
+// ToStd turns a gee middleware into a net/http middleware, c.Next() calls
+// the next http.Handler. Inside a gee chain, e.g. under FromStd, the
+// middleware shares the Keys, Params and engine of the outer Context.
+func ToStd(h HandlerFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			parent, ok := ContextFromRequest(req)
+			var c *Context
+			if ok {
+				if parent.Keys == nil {
+					parent.Keys = make(map[string]interface{})
+				}
+				cp := *parent
+				c = &cp
+				c.Writer, c.Req = w, req
+				c.index = -1
+			} else {
+				c = newContext(w, req)
+			}
+			c.handlers = []HandlerFunc{h, func(c *Context) {
+				next.ServeHTTP(c.Writer, withContext(c.Req, c))
+			}}
+			c.Next()
+			if ok && c.StatusCode != 0 {
+				parent.StatusCode = c.StatusCode
+			}
+		})
+	}
+}
+
+func withContext(req *http.Request, c *Context) *http.Request {
+	if current, ok := ContextFromRequest(req); ok && current == c {
+		return req
+	}
+	return req.WithContext(context.WithValue(req.Context(), contextKey{}, c))
+}
@@ -0,0 +1,76 @@
+package gee
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type stdKey struct{}
+
+func TestFromStd(t *testing.T) {
+	stamp := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("X-Std", "1")
+			next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), stdKey{}, "ctx")))
+		})
+	}
+	deny := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Header.Get("X-Deny") != "" {
+				http.Error(w, "denied", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+	r := New()
+	r.Use(FromStd(stamp), FromStd(deny), FromStd(ToStd(func(c *Context) {
+		c.Set("user", "tom")
+		c.Next()
+	})))
+	r.GET("/", func(c *Context) {
+		user, _ := c.Get("user")
+		c.String(http.StatusOK, "%v %v", user, c.Req.Context().Value(stdKey{}))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Body.String() != "tom ctx" || w.Header().Get("X-Std") != "1" {
+		t.Fatalf("got %q %q", w.Body.String(), w.Header().Get("X-Std"))
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Deny", "1")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden || w.Body.String() != "denied\n" {
+		t.Fatalf("short-circuit should abort the chain, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestToStd(t *testing.T) {
+	auth := ToStd(BasicAuth(Accounts{"tom": "secret"}))
+	h := auth(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c, ok := ContextFromRequest(req)
+		if !ok {
+			t.Fatal("the gee Context should be reachable from the request")
+		}
+		user, _ := c.Get(AuthUserKey)
+		w.Write([]byte(user.(string)))
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("missing credentials should be rejected, got %d", w.Code)
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.SetBasicAuth("tom", "secret")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Body.String() != "tom" {
+		t.Fatalf("got %q", w.Body.String())
+	}
+}
@@ -0,0 +1,184 @@
+package gee
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Templates renders per-page template sets. Every page is parsed from its
+// own files, so pages can fill the blocks of a shared layout differently:
+//
+//	t := gee.NewTemplates()
+//	r.SetHTMLRender(t) // gives t the funcs of r.SetFuncMap
+//	t.Add("index", "templates/base.tmpl", "templates/partials/*.tmpl", "templates/index.tmpl")
+//	c.HTML(http.StatusOK, "index", data) // executes base.tmpl
+//
+// The first file of a set is the one executed. Funcs used by the templates
+// must be set before the sets using them are added.
+type Templates struct {
+	// Reload reparses a set when one of its files changed, for development.
+	// It defaults to IsDebugging() when the Templates are created.
+	Reload bool
+
+	mu      sync.RWMutex
+	fsys    fs.FS
+	funcMap template.FuncMap
+	sets    map[string]*templateSet
+}
+
+type templateSet struct {
+	patterns  []string
+	tmpl      *template.Template
+	signature string
+}
+
+// NewTemplates reads template files from the local file system
+func NewTemplates() *Templates {
+	return &Templates{Reload: IsDebugging(), funcMap: template.FuncMap{}, sets: make(map[string]*templateSet)}
+}
+
+// NewTemplatesFS reads template files from fsys, e.g. an embed.FS
+func NewTemplatesFS(fsys fs.FS) *Templates {
+	t := NewTemplates()
+	t.fsys = fsys
+	return t
+}
+
+// SetFuncMap adds funcs to every set, the sets already added are reparsed
+// and it panics like Add when they no longer parse
+func (t *Templates) SetFuncMap(funcMap template.FuncMap) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for k, v := range funcMap {
+		t.funcMap[k] = v
+	}
+	for _, set := range t.sets {
+		if err := t.parse(set); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Add parses the files matching patterns as the set called name, it panics
+// like template.Must when they can not be parsed
+func (t *Templates) Add(name string, patterns ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	set := &templateSet{patterns: patterns}
+	if err := t.parse(set); err != nil {
+		panic(err)
+	}
+	t.sets[name] = set
+}
+
+// Render executes the set called name
+func (t *Templates) Render(w io.Writer, name string, data interface{}) error {
+	t.mu.RLock()
+	set, ok := t.sets[name]
+	t.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("gee: template set %q is not defined", name)
+	}
+
+	if t.Reload {
+		t.mu.Lock()
+		if signature, err := t.signature(set); err != nil || signature != set.signature {
+			if err := t.parse(set); err != nil {
+				t.mu.Unlock()
+				return err
+			}
+		}
+		t.mu.Unlock()
+	}
+
+	t.mu.RLock()
+	tmpl := set.tmpl
+	t.mu.RUnlock()
+	return tmpl.Execute(w, data)
+}
+
+func (t *Templates) files(set *templateSet) ([]string, error) {
+	var files []string
+	for _, pattern := range set.patterns {
+		var matches []string
+		var err error
+		if t.fsys != nil {
+			matches, err = fs.Glob(t.fsys, pattern)
+		} else {
+			matches, err = filepath.Glob(pattern)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("gee: pattern matches no files: %q", pattern)
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// signature changes whenever a file is added, removed or modified
+func (t *Templates) signature(set *templateSet) (string, error) {
+	files, err := t.files(set)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, file := range files {
+		var info fs.FileInfo
+		if t.fsys != nil {
+			info, err = fs.Stat(t.fsys, file)
+		} else {
+			info, err = os.Stat(file)
+		}
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "%s:%d:%d;", file, info.Size(), info.ModTime().UnixNano())
+	}
+	return b.String(), nil
+}
+
+func (t *Templates) parse(set *templateSet) error {
+	files, err := t.files(set)
+	if err != nil {
+		return err
+	}
+	signature, err := t.signature(set)
+	if err != nil {
+		return err
+	}
+
+	tmpl := template.New(path.Base(filepath.ToSlash(files[0]))).Funcs(t.funcMap)
+	for _, file := range files {
+		var b []byte
+		if t.fsys != nil {
+			b, err = fs.ReadFile(t.fsys, file)
+		} else {
+			b, err = os.ReadFile(file)
+		}
+		if err == nil {
+			// like template.ParseFiles, the first file fills the root template
+			target := tmpl
+			if name := path.Base(filepath.ToSlash(file)); name != tmpl.Name() {
+				target = tmpl.New(name)
+			}
+			_, err = target.Parse(string(b))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	set.tmpl, set.signature = tmpl, signature
+	return nil
+}
@@ -0,0 +1,89 @@
+package gee
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeTemplate(t *testing.T, dir, name, content string, mtime time.Time) {
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(p, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTemplates(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	writeTemplate(t, dir, "base.tmpl", `<h1>{{block "title" .}}gee{{end}}</h1>{{template "content" .}}`, now)
+	writeTemplate(t, dir, "index.tmpl", `{{define "content"}}hello {{.name | upper}}{{end}}`, now)
+	writeTemplate(t, dir, "about.tmpl", `{{define "title"}}about{{end}}{{define "content"}}{{.missing.field}}{{end}}`, now)
+
+	r := New()
+	r.SetFuncMap(map[string]interface{}{"upper": func(s string) string { return s + "!" }})
+	templates := NewTemplates()
+	templates.Reload = true
+	r.SetHTMLRender(templates)
+	templates.Add("index", filepath.Join(dir, "base.tmpl"), filepath.Join(dir, "index.tmpl"))
+	templates.Add("about", filepath.Join(dir, "base.tmpl"), filepath.Join(dir, "about.tmpl"))
+	r.GET("/:page", func(c *Context) {
+		c.HTML(http.StatusOK, c.Param("page"), H{"name": "gee", "missing": 1})
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+	if w := serve("/index"); w.Body.String() != "<h1>gee</h1>hello gee!" {
+		t.Fatalf("unexpected page %q", w.Body.String())
+	}
+	if w := serve("/about"); w.Code != http.StatusInternalServerError || w.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("failing template should yield a 500, got %d %q", w.Code, w.Body.String())
+	}
+	if w := serve("/nope"); w.Code != http.StatusInternalServerError {
+		t.Fatalf("unknown set should yield a 500, got %d", w.Code)
+	}
+
+	writeTemplate(t, dir, "index.tmpl", `{{define "content"}}bye{{end}}`, now.Add(time.Second))
+	if w := serve("/index"); w.Body.String() != "<h1>gee</h1>bye" {
+		t.Fatalf("changed template should be reloaded, got %q", w.Body.String())
+	}
+}
+
+func TestTemplatesFuncMapAfterAdd(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, "page.tmpl", `{{shout .}}`, time.Now())
+	templates := NewTemplates()
+	templates.SetFuncMap(map[string]interface{}{"shout": func(s string) string { return s }})
+	templates.Add("page", filepath.Join(dir, "page.tmpl"))
+
+	r := New()
+	r.SetHTMLRender(templates)
+	r.SetFuncMap(map[string]interface{}{"shout": func(s string) string { return s + "!" }})
+	r.GET("/", func(c *Context) { c.HTML(http.StatusOK, "page", "hi") })
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Body.String() != "hi!" {
+		t.Fatalf("engine funcs should apply to sets added before, got %q", w.Body.String())
+	}
+}
+
+func TestTemplatesReloadInDebugMode(t *testing.T) {
+	defer SetMode(Mode())
+	SetMode(DebugMode)
+	if !NewTemplates().Reload {
+		t.Fatal("templates should reload in debug mode")
+	}
+	SetMode(ReleaseMode)
+	if NewTemplates().Reload {
+		t.Fatal("templates should not reload in release mode")
+	}
+}
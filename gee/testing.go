@@ -0,0 +1,14 @@
+package gee
+
+import "net/http"
+
+// CreateTestContext returns a Context for req bound to a new Engine. Its
+// chain is handlers, so a middleware can be unit tested in isolation by
+// calling c.Next() with the middleware followed by a stub handler.
+func CreateTestContext(w http.ResponseWriter, req *http.Request, handlers ...HandlerFunc) (*Context, *Engine) {
+	engine := New()
+	c := newContext(w, req)
+	c.engine = engine
+	c.handlers = handlers
+	return c, engine
+}
@@ -0,0 +1,105 @@
+package gee
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TimeoutConfig configures the Timeout middleware
+type TimeoutConfig struct {
+	Timeout time.Duration
+	// Response answers requests whose handlers did not finish in time,
+	// defaults to a 503 "Service Unavailable"
+	Response HandlerFunc
+}
+
+// Timeout returns a middleware aborting the rest of the chain after d
+func Timeout(d time.Duration) HandlerFunc {
+	return TimeoutWithConfig(TimeoutConfig{Timeout: d})
+}
+
+// TimeoutWithConfig runs the rest of the chain in its own goroutine on a copy
+// of the Context whose request carries the deadline and whose Writer is a
+// buffer. The buffer is copied to the client only if the chain finishes in
+// time, late writes of an expired handler are discarded.
+func TimeoutWithConfig(config TimeoutConfig) HandlerFunc {
+	if config.Response == nil {
+		config.Response = func(c *Context) {
+			c.Fail(http.StatusServiceUnavailable, "Service Unavailable")
+		}
+	}
+	return func(c *Context) {
+		ctx, cancel := context.WithTimeout(c.Req.Context(), config.Timeout)
+		defer cancel()
+
+		tw := &timeoutWriter{responseRecorder: newResponseRecorder()}
+		for k, v := range c.Writer.Header() {
+			tw.header[k] = append([]string(nil), v...)
+		}
+		cp := *c
+		cp.Writer = tw
+		cp.Req = c.Req.WithContext(ctx)
+		cp.Keys = make(map[string]interface{}, len(c.Keys))
+		for k, v := range c.Keys {
+			cp.Keys[k] = v
+		}
+
+		done := make(chan struct{})
+		panicked := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicked <- p
+				}
+			}()
+			cp.Next()
+			close(done)
+		}()
+
+		select {
+		case p := <-panicked:
+			c.Abort()
+			panic(p)
+		case <-done:
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			tw.writeTo(c.Writer)
+			c.StatusCode = cp.StatusCode
+			c.Keys = cp.Keys
+			c.index = cp.index
+		case <-ctx.Done():
+			tw.mu.Lock()
+			tw.timedOut = true
+			tw.mu.Unlock()
+			c.Abort()
+			config.Response(c)
+		}
+	}
+}
+
+// timeoutWriter guards the buffer so an expired handler can keep writing
+// from its goroutine without racing with the response sent on its behalf
+type timeoutWriter struct {
+	*responseRecorder
+	mu       sync.Mutex
+	timedOut bool
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.timedOut {
+		w.responseRecorder.WriteHeader(code)
+	}
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	return w.responseRecorder.Write(b)
+}
@@ -0,0 +1,140 @@
+package gee
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpanKey is the context key holding the *Span of the request
+const SpanKey = "span"
+
+// SpanContext identifies a span across process boundaries, see
+// https://www.w3.org/TR/trace-context/
+type SpanContext struct {
+	TraceID string // 32 hex digits
+	SpanID  string // 16 hex digits
+	Sampled bool
+}
+
+// ParseTraceParent parses a version 00 traceparent header
+func ParseTraceParent(header string) (SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" ||
+		(parts[0] == "00" && len(parts) != 4) {
+		return SpanContext{}, false
+	}
+	traceID, spanID, flags := parts[1], parts[2], parts[3]
+	if !isHex(traceID, 32) || !isHex(spanID, 16) || !isHex(flags, 2) ||
+		traceID == strings.Repeat("0", 32) || spanID == strings.Repeat("0", 16) {
+		return SpanContext{}, false
+	}
+	f, _ := hex.DecodeString(flags)
+	return SpanContext{TraceID: traceID, SpanID: spanID, Sampled: f[0]&1 == 1}, true
+}
+
+// TraceParent formats sc as a traceparent header value
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", sc.TraceID, sc.SpanID, flags)
+}
+
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !(s[i] >= '0' && s[i] <= '9' || s[i] >= 'a' && s[i] <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Span records one request handled by the server
+type Span struct {
+	SpanContext
+	ParentID   string
+	Name       string
+	Start      time.Time
+	End        time.Time
+	Status     int
+	Attributes map[string]string
+}
+
+// SpanExporter receives finished spans
+type SpanExporter interface {
+	ExportSpan(span *Span)
+}
+
+// InMemoryExporter keeps finished spans in memory, for tests
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+func (e *InMemoryExporter) ExportSpan(span *Span) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+// Spans returns the spans exported so far
+func (e *InMemoryExporter) Spans() []*Span {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]*Span(nil), e.spans...)
+}
+
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
+
+// Trace continues the trace of an incoming traceparent header, or starts
+// a new one, and exports a span named after the matched route per request.
+// The span is stored under SpanKey and its traceparent is echoed so clients
+// can correlate responses.
+func Trace(exporter SpanExporter) HandlerFunc {
+	return func(c *Context) {
+		span := &Span{Start: time.Now(), Attributes: make(map[string]string)}
+		if parent, ok := ParseTraceParent(c.Req.Header.Get("traceparent")); ok {
+			span.TraceID, span.ParentID, span.Sampled = parent.TraceID, parent.SpanID, parent.Sampled
+		} else {
+			span.TraceID, span.Sampled = randomHex(16), true
+		}
+		span.SpanID = randomHex(8)
+		span.Attributes["http.method"] = c.Method
+		span.Attributes["http.target"] = c.Req.URL.RequestURI()
+		if id := c.RequestID(); id != "" {
+			span.Attributes["request.id"] = id
+		}
+		c.Set(SpanKey, span)
+		c.SetHeader("traceparent", span.TraceParent())
+
+		c.Next()
+
+		span.End = time.Now()
+		span.Status = c.StatusCode
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		span.Name = c.Method + " " + route
+		if span.Sampled {
+			exporter.ExportSpan(span)
+		}
+	}
+}
+
+// Span returns the span started by the Trace middleware, or nil
+func (c *Context) Span() *Span {
+	span, _ := c.Keys[SpanKey].(*Span)
+	return span
+}
@@ -0,0 +1,65 @@
+package gee
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	sc, ok := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if !ok || sc.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID != "00f067aa0ba902b7" || !sc.Sampled {
+		t.Fatalf("unexpected span context %+v", sc)
+	}
+	if sc.TraceParent() != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Fatalf("TraceParent() = %s", sc.TraceParent())
+	}
+	for _, header := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		if _, ok := ParseTraceParent(header); ok {
+			t.Fatalf("%q should be rejected", header)
+		}
+	}
+}
+
+func TestRequestIDAndTrace(t *testing.T) {
+	exporter := &InMemoryExporter{}
+	r := New()
+	r.Use(RequestID(), Trace(exporter))
+	r.GET("/users/:id", func(c *Context) {
+		c.String(http.StatusOK, c.RequestID())
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/users/1", nil)
+	req.Header.Set("X-Request-ID", "abc")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(w, req)
+
+	if w.Body.String() != "abc" || w.Header().Get("X-Request-ID") != "abc" {
+		t.Fatalf("request id should be reused and echoed, got %q", w.Body.String())
+	}
+	spans := exporter.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "GET /users/:id" || span.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" ||
+		span.ParentID != "00f067aa0ba902b7" || span.Status != http.StatusOK || span.Attributes["request.id"] != "abc" {
+		t.Fatalf("unexpected span %+v", span)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/users/2", nil))
+	if len(w.Header().Get("X-Request-ID")) != 32 {
+		t.Fatalf("expected a generated request id, got %q", w.Header().Get("X-Request-ID"))
+	}
+	if spans = exporter.Spans(); len(spans) != 2 || spans[1].ParentID != "" {
+		t.Fatal("a request without traceparent should start a new trace")
+	}
+}
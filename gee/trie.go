@@ -7,6 +7,7 @@ import (
 
 type node struct {
 	pattern 	string 	 	//left part of string to be matched
+	slashPattern string		//the same route registered with a trailing slash
 	part 		string		//:lang
 	children    []*node
 	isWild 		bool 		//is strict match? part has : or * is true
@@ -16,32 +17,40 @@ func (n *node) String() string {
 	return fmt.Sprintf("node{pattern=%s, part=%s, isWild=%t}", n.pattern, n.part, n.isWild)
 }
 
-//return first match child node (used for insert)
+//return the child node with the same part (used for insert), wildcards
+//only share a node when named alike so static siblings keep their own
 func (n *node)matchChild(part string) *node{
 	for _,child :=range n.children{
-		if child.pattern==part || child.isWild{
+		if child.part==part{
 			return child
 		}
 	}
 	return nil
 }
 
-//match all child nodes!
+//match all child nodes, static ones first so they win over wildcards
 func (n *node)matchChildren(part string) []*node{
 	nodes:=make([]*node,0)
+	var wild []*node
 	for _,child :=range n.children{
-		child.String()
-		if child.part==part || child.isWild{
+		_ = child.String()
+		if child.isWild{
+			wild=append(wild,child)
+		}else if child.part==part{
 			nodes=append(nodes,child)
 		}
 	}
-	return nodes
+	return append(nodes,wild...)
 }
 
 //parts is the url formatted!
 func (n *node)insert(pattern string,parts []string,height int){
 	if len(parts)==height{
-		n.pattern=pattern
+		if len(pattern)>1 && strings.HasSuffix(pattern,"/"){
+			n.slashPattern=pattern
+		}else{
+			n.pattern=pattern
+		}
 		return
 	}
 	part:=parts[height]
@@ -56,7 +65,7 @@ func (n *node)insert(pattern string,parts []string,height int){
 //parts is the url formatted!
 func (n *node)search(parts []string,height int)*node{
 	if len(parts)==height||strings.HasPrefix(n.part,"*"){
-		if n.pattern==""{
+		if n.pattern==""&&n.slashPattern==""{
 			return nil
 		}
 		return n
@@ -73,11 +82,46 @@ func (n *node)search(parts []string,height int)*node{
 }
 
 
+// route returns the pattern serving path, the one registered with a
+// trailing slash when both exist and path has one
+func (n *node) route(path string) string {
+	if n.slashPattern != "" && (n.pattern == "" || strings.HasSuffix(path, "/")) {
+		return n.slashPattern
+	}
+	return n.pattern
+}
+
 func (n *node) travel(list *([]*node)) {
-	if n.pattern != "" {
+	if n.pattern != "" || n.slashPattern != "" {
 		*list = append(*list, n)
 	}
 	for _, child := range n.children {
 		child.travel(list)
 	}
-}
\ No newline at end of file
+}
+
+// searchFold is search comparing static parts case-insensitively, it also
+// returns the parts spelled as in the pattern
+func (n *node) searchFold(parts []string, height int) (*node, []string) {
+	if len(parts) == height || strings.HasPrefix(n.part, "*") {
+		if n.pattern == "" && n.slashPattern == "" {
+			return nil, nil
+		}
+		return n, parts
+	}
+	part := parts[height]
+	for _, child := range n.children {
+		if !child.isWild && !strings.EqualFold(child.part, part) {
+			continue
+		}
+		fixed := parts
+		if !child.isWild && child.part != part {
+			fixed = append([]string(nil), parts...)
+			fixed[height] = child.part
+		}
+		if result, fixed := child.searchFold(fixed, height+1); result != nil {
+			return result, fixed
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,74 @@
+package gee
+
+import (
+	"net/http"
+	"strings"
+)
+
+// APIVersionKey is the key under which the version selected by the
+// Accept header is stored in Context.Keys
+const APIVersionKey = "apiVersion"
+
+// VersionConfig dispatches requests to version groups by their Accept
+// header, e.g. "Accept: application/vnd.x.v2+json" to the group of "v2"
+type VersionConfig struct {
+	// Vendor is the x of application/vnd.x.v2+json
+	Vendor string
+	// Groups maps each version to the group serving it
+	Groups map[string]*RouterGroup
+	// Default is the version of requests naming none, "" answers them
+	// from the unversioned routes
+	Default string
+}
+
+// Versions enables header versioning. Requests whose path does not start
+// with the prefix of a version group are routed to the group of their
+// version, /users with v2 being served by the route /v2/users. Paths the
+// version group has no route for are routed unchanged, so unversioned
+// routes such as /health keep working. Other requests naming an unknown
+// version get 406.
+func (engine *Engine) Versions(config VersionConfig) {
+	if config.Default != "" && config.Groups[config.Default] == nil {
+		panic("gee: no group for the default version " + config.Default)
+	}
+	engine.versions = &config
+}
+
+// versionPath returns the path a request is routed by and its version,
+// ok is false when the version is unknown
+func (config *VersionConfig) versionPath(req *http.Request) (p string, version string, ok bool) {
+	p = req.URL.Path
+	for _, group := range config.Groups {
+		if p == group.prefix || strings.HasPrefix(p, group.prefix+"/") {
+			return p, "", true
+		}
+	}
+	version = config.acceptVersion(req.Header.Values("Accept"))
+	if version == "" {
+		version = config.Default
+	}
+	if version == "" {
+		return p, "", true
+	}
+	group, ok := config.Groups[version]
+	if !ok {
+		return p, version, false
+	}
+	return group.prefix + p, version, true
+}
+
+// acceptVersion returns the version of the first vendor media type accepted
+func (config *VersionConfig) acceptVersion(accept []string) string {
+	prefix := "application/vnd." + config.Vendor + "."
+	for _, value := range accept {
+		for _, mediaType := range strings.Split(value, ",") {
+			mediaType, _, _ = strings.Cut(mediaType, ";")
+			mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+			if version, ok := strings.CutPrefix(mediaType, prefix); ok {
+				version, _, _ = strings.Cut(version, "+")
+				return version
+			}
+		}
+	}
+	return ""
+}
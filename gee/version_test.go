@@ -0,0 +1,87 @@
+package gee
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeprecated(t *testing.T) {
+	sunset := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := New()
+	v1 := r.Group("/v1").Deprecate(DeprecationConfig{
+		Date:   time.Unix(1700000000, 0),
+		Sunset: sunset,
+		Link:   "https://example.com/migrate",
+	})
+	v1.GET("/users", func(c *Context) { c.String(http.StatusOK, "v1") })
+	r.GET("/old", Chain(func(c *Context) { c.String(http.StatusOK, "old") }, Deprecated(DeprecationConfig{
+		Sunset:          time.Now().Add(-time.Hour),
+		GoneAfterSunset: true,
+	})))
+	r.GET("/new", func(c *Context) { c.String(http.StatusOK, "new") })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/v1/users", nil))
+	if w.Header().Get("Deprecation") != "@1700000000" || w.Header().Get("Sunset") != "Tue, 01 Jan 2030 00:00:00 GMT" ||
+		w.Header().Get("Link") != `<https://example.com/migrate>; rel="deprecation"` || w.Body.String() != "v1" {
+		t.Fatalf("unexpected response %v %q", w.Header(), w.Body.String())
+	}
+	for _, p := range []string{"/old", "/old", "/new"} {
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", p, nil))
+	}
+	if w.Header().Get("Deprecation") != "" {
+		t.Fatal("routes not deprecated should not get the headers")
+	}
+	usage := r.DeprecatedUsage()
+	if usage["GET /v1/users"] != 1 || usage["GET /old"] != 2 || len(usage) != 2 {
+		t.Fatalf("usage = %v", usage)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/old", nil))
+	if w.Code != http.StatusGone || w.Header().Get("Deprecation") != "true" {
+		t.Fatalf("route past its sunset should be gone, got %d", w.Code)
+	}
+}
+
+func TestVersions(t *testing.T) {
+	r := New()
+	v1, v2 := r.Group("/v1"), r.Group("/v2")
+	handler := func(c *Context) {
+		version, _ := c.Get(APIVersionKey)
+		c.String(http.StatusOK, "%s %v", c.FullPath(), version)
+	}
+	v1.GET("/users", handler)
+	v2.GET("/users", handler)
+	r.GET("/health", handler)
+	r.Versions(VersionConfig{Vendor: "x", Groups: map[string]*RouterGroup{"v1": v1, "v2": v2}, Default: "v1"})
+
+	tests := []struct {
+		path, accept, body string
+		code               int
+	}{
+		{"/users", "application/vnd.x.v2+json", "/v2/users v2", http.StatusOK},
+		{"/users", "text/html, application/vnd.x.v1+json;q=0.9", "/v1/users v1", http.StatusOK},
+		{"/users", "", "/v1/users v1", http.StatusOK},
+		{"/v2/users", "application/vnd.x.v1+json", "/v2/users <nil>", http.StatusOK},
+		{"/users", "application/vnd.x.v3+json", "{\"message\":\"Not Acceptable\"}\n", http.StatusNotAcceptable},
+		{"/health", "", "/health <nil>", http.StatusOK},
+		{"/health", "application/vnd.x.v2+json", "/health <nil>", http.StatusOK},
+		{"/health", "application/vnd.x.v3+json", "/health <nil>", http.StatusOK},
+		{"/missing", "", "404 NOT FOUND: /missing \n", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.code || w.Body.String() != tt.body || w.Header().Get("Vary") != "Accept" {
+			t.Fatalf("%s %q = %d %q, want %d %q", tt.path, tt.accept, w.Code, w.Body.String(), tt.code, tt.body)
+		}
+	}
+}
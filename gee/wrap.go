@@ -0,0 +1,82 @@
+package gee
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// WrapF adapts a net/http handler function to a HandlerFunc
+func WrapF(f http.HandlerFunc) HandlerFunc {
+	return WrapH(f)
+}
+
+// WrapH adapts a net/http handler to a HandlerFunc, the status it writes
+// is recorded in c.StatusCode
+func WrapH(h http.Handler) HandlerFunc {
+	return func(c *Context) {
+		h.ServeHTTP(&statusWriter{ResponseWriter: c.Writer, c: c}, c.Req)
+	}
+}
+
+// Mount serves h under prefix, with prefix stripped from the request path,
+// e.g. group.Mount("/debug/pprof", mux) or another *Engine, prefix itself
+// being redirected to prefix+"/" by RedirectTrailingSlash. The group's
+// middlewares run before h. Handlers expecting the full path, such as the
+// geecache HttpPool, should be registered with Any and WrapH instead.
+func (group *RouterGroup) Mount(prefix string, h http.Handler) {
+	if strings.Contains(prefix, ":") || strings.Contains(prefix, "*") {
+		panic("gee: URL parameters can not be used when mounting a handler")
+	}
+	absolute := path.Join("/", group.prefix, prefix)
+	handler := func(c *Context) {
+		req := new(http.Request)
+		*req = *c.Req
+		req.URL = new(url.URL)
+		*req.URL = *c.Req.URL
+		req.URL.Path = "/" + c.Param("filepath")
+		if c.Req.URL.RawPath != "" {
+			req.URL.RawPath = strings.TrimPrefix(c.Req.URL.RawPath, absolute)
+			if !strings.HasPrefix(req.URL.RawPath, "/") {
+				req.URL.RawPath = "/" + req.URL.RawPath
+			}
+		}
+		h.ServeHTTP(&statusWriter{ResponseWriter: c.Writer, c: c}, req)
+	}
+	// the prefix is registered with a trailing slash so it is redirected
+	// to, keeping the relative links of index pages like pprof's working
+	group.Any(strings.TrimSuffix(prefix, "/")+"/", handler)
+	group.Any(path.Join(prefix, "/*filepath"), handler)
+}
+
+// statusWriter records the status written by a net/http handler
+type statusWriter struct {
+	http.ResponseWriter
+	c *Context
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.c.StatusCode == 0 {
+		w.c.StatusCode = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.c.StatusCode == 0 {
+		w.c.StatusCode = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,63 @@
+package gee
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMount(t *testing.T) {
+	sub := New()
+	sub.GET("/users/:name", func(c *Context) {
+		c.String(http.StatusOK, "%s %s", c.Param("name"), c.Req.URL.Path)
+	})
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte(req.URL.Path))
+	})
+
+	r := New()
+	var status int
+	api := r.Group("/api")
+	api.Use(func(c *Context) {
+		c.SetHeader("X-Outer", "1")
+		c.Next()
+		status = c.StatusCode
+	})
+	api.Mount("/v1", sub)
+	r.Mount("/std", mux)
+	r.GET("/f", WrapF(func(w http.ResponseWriter, req *http.Request) { w.Write([]byte("f")) }))
+
+	tests := []struct {
+		path, body string
+		code       int
+	}{
+		{"/api/v1/users/tom", "tom /users/tom", http.StatusOK},
+		{"/std/", "/", http.StatusTeapot},
+		{"/std/a/b", "/a/b", http.StatusTeapot},
+		{"/f", "f", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Code != tt.code || w.Body.String() != tt.body {
+			t.Fatalf("%s = %d %q, want %d %q", tt.path, w.Code, w.Body.String(), tt.code, tt.body)
+		}
+	}
+
+	// index pages like pprof's link relatively, so they keep their slash
+	for _, p := range []string{"/std", "/api/v1"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", p, nil))
+		if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != p+"/" {
+			t.Fatalf("%s = %d %q, want a redirect to %s/", p, w.Code, w.Header().Get("Location"), p)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/missing", nil))
+	if w.Code != http.StatusNotFound || w.Header().Get("X-Outer") != "1" || status != http.StatusNotFound {
+		t.Fatalf("outer middleware should wrap the sub-engine, got %d %q %d", w.Code, w.Header().Get("X-Outer"), status)
+	}
+}
@@ -60,12 +60,20 @@ func (g *Group) Get(key string) (ByteView, error) {
 		return ByteView{}, fmt.Errorf("key nil!")
 	}
 	if v, ok := g.maincache.get(key); ok {
-		log.Println("hit")
+		// hits are the hot path, e.g. of gee's rate limit store, so they are not logged
 		return v, nil
 	}
 	return g.load(key)
 }
 
+// Set populates the local cache directly, peers are not updated
+func (g *Group) Set(key string, value []byte) {
+	if key == "" {
+		return
+	}
+	g.maincache.add(key, ByteView{b: cloneBytes(value)})
+}
+
 func (g *Group) load(key string) (value ByteView, err error) {
 	viewi, err := g.loader.Do(key, func() (interface{}, error) {
 		if g.peers != nil {
@@ -64,4 +64,22 @@ func TestGetGroup(t *testing.T) {
 	if group := GetGroup(groupName + "111"); group != nil {
 		t.Fatalf("expect nil, but %s got", group.name)
 	}
-}
\ No newline at end of file
+}
+
+func TestSet(t *testing.T) {
+	loads := 0
+	gee := NewGroup("counters", 2<<10, GetterFunc(
+		func(key string) ([]byte, error) {
+			loads++
+			return nil, fmt.Errorf("%s not exist", key)
+		}))
+
+	gee.Set("Tom", []byte("1"))
+	if view, err := gee.Get("Tom"); err != nil || view.String() != "1" || loads != 0 {
+		t.Fatalf("expect value set locally, got %v %v", view, err)
+	}
+	gee.Set("Tom", []byte("2"))
+	if view, _ := gee.Get("Tom"); view.String() != "2" {
+		t.Fatalf("expect value overwritten, got %s", view)
+	}
+}